@@ -0,0 +1,254 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/giannimassi/trello-tui/pkg/boardfile"
+	"github.com/giannimassi/trello-tui/pkg/domain"
+	"github.com/giannimassi/trello-tui/pkg/export"
+	"github.com/giannimassi/trello-tui/pkg/importer"
+	"github.com/giannimassi/trello-tui/pkg/markdown"
+	"github.com/giannimassi/trello-tui/pkg/state"
+	"github.com/giannimassi/trello-tui/pkg/trello"
+	"github.com/giannimassi/trello-tui/pkg/trello/fake"
+)
+
+// subcommand is a command run in place of the gui, e.g. `trello-tui export`
+type subcommand struct {
+	description string
+	run         func(args []string) error
+}
+
+// subcommands are the available subcommands, by name
+var subcommands = map[string]subcommand{
+	"boards": {description: "print the names of the boards", run: runBoards},
+	"lists":  {description: "print the lists of a board", run: runLists},
+	"cards":  {description: "print the cards of a board, optionally of a list or with a label", run: runCards},
+	"card":   {description: "show, add, move or comment a card", run: runCard},
+	"export": {description: "write a board to a Markdown, JSON or CSV file", run: runExport},
+	"import": {description: "create cards in a list from a CSV file or a Markdown checklist", run: runImport},
+}
+
+// runSubcommand runs the subcommand named by the first argument, it returns false if the
+// arguments do not start with a subcommand
+func runSubcommand(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	cmd, found := subcommands[args[0]]
+	if !found {
+		return false
+	}
+	log.Logger = log.Output(ioutil.Discard)
+	zerolog.SetGlobalLevel(zerolog.Disabled)
+	err := cmd.run(args[1:])
+	switch {
+	case err == flag.ErrHelp:
+		// the usage has been printed already
+	case err != nil:
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	return true
+}
+
+// subcommandsUsage describes the available subcommands
+func subcommandsUsage() string {
+	names := make([]string, 0, len(subcommands))
+	for name := range subcommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var sb strings.Builder
+	sb.WriteString("Subcommands (run `trello-tui <subcommand> -h` for their flags):\n")
+	for _, name := range names {
+		fmt.Fprintf(&sb, "  %-8s %s\n", name, subcommands[name].description)
+	}
+	return sb.String()
+}
+
+// newFlagSet returns the flag set of the subcommand with the provided name and usage
+func newFlagSet(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: trello-tui %s %s\n", name, usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// sourceFlags are the flags selecting where boards are loaded from, shared by the subcommands
+type sourceFlags struct {
+	board *string
+	file  *string
+	demo  *bool
+}
+
+// addSourceFlags registers the flags selecting the board source
+func addSourceFlags(fs *flag.FlagSet) *sourceFlags {
+	return &sourceFlags{
+		board: fs.String("board", "", "board name"),
+		file:  fs.String("file", "", "board file (.json, .yaml, .md) or directory of board files used instead of trello"),
+		demo:  fs.Bool("demo", false, "use the demo board served locally, no trello account required"),
+	}
+}
+
+// source returns the initialized board source selected by the flags and a function releasing it
+func (f *sourceFlags) source() (state.BoardSource, func(), error) {
+	var (
+		source  state.BoardSource
+		cleanup = func() {}
+	)
+	switch {
+	case *f.file != "" && *f.demo:
+		return nil, nil, errors.New("the -file and -demo flags cannot be used together")
+	case *f.file != "":
+		source = fileSource(*f.file)
+	case *f.demo:
+		server := fake.NewDemoServer()
+		cleanup = server.Close
+		source = trello.NewClient(&trello.Config{
+			User:    fake.DemoUser,
+			Key:     "demo",
+			Token:   "demo",
+			Timeout: trelloTimeout,
+			BaseURL: server.APIURL(),
+		})
+		if *f.board == "" {
+			*f.board = fake.DemoBoard
+		}
+	default:
+		source = trello.NewClient(trelloConfig())
+	}
+	if err := source.Init(); err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	return source, cleanup, nil
+}
+
+// loadBoard returns the board selected by the flags and its source
+func (f *sourceFlags) loadBoard() (state.BoardSource, *domain.Board, func(), error) {
+	source, cleanup, err := f.source()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	b, err := source.Board(*f.board)
+	if err != nil {
+		cleanup()
+		return nil, nil, nil, errors.Wrapf(err, "could not load board %q", *f.board)
+	}
+	return source, b, cleanup, nil
+}
+
+// fileSource returns the board source for the file or directory at the provided path
+func fileSource(path string) state.BoardSource {
+	if markdown.IsMarkdownFile(path) {
+		return markdown.NewSource(path)
+	}
+	return boardfile.NewSource(path)
+}
+
+// trelloConfig returns the trello configuration from the environment
+func trelloConfig() *trello.Config {
+	return &trello.Config{
+		User:    os.Getenv(TrelloUser),
+		Key:     os.Getenv(TrelloKey),
+		Token:   os.Getenv(TrelloToken),
+		Timeout: trelloTimeout,
+	}
+}
+
+// runExport writes a board to a file or to stdout
+func runExport(args []string) error {
+	fs := newFlagSet("export", "-board <name> [-format md|json|csv] [-o path]")
+	sf := addSourceFlags(fs)
+	format := fs.String("format", "", fmt.Sprintf("export format (%s), by default taken from the output file extension or md", strings.Join(export.Formats(), ", ")))
+	output := fs.String("o", "", "output file, stdout if empty")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	_, b, cleanup, err := sf.loadBoard()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if *output != "" {
+		return export.WriteFile(*output, b, *format)
+	}
+	if *format == "" {
+		*format = export.Markdown
+	}
+	return export.Write(os.Stdout, b, *format)
+}
+
+// runImport creates the cards of a file in a list, after listing them
+func runImport(args []string) error {
+	fs := newFlagSet("import", "-board <name> -list <name> [-dry-run] <file.csv|file.md>")
+	sf := addSourceFlags(fs)
+	listName := fs.String("list", "", "name of the list the cards are added to")
+	dryRun := fs.Bool("dry-run", false, "list the cards to import without creating them")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("expected the path of the file to import")
+	}
+
+	source, b, cleanup, err := sf.loadBoard()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	listIdx, err := findList(b, *listName)
+	if err != nil {
+		return err
+	}
+	labels, err := source.Labels(b.ID)
+	if err != nil {
+		return errors.Wrapf(err, "could not load the labels of board %q", b.Name)
+	}
+	plan, err := importer.NewPlan(fs.Arg(0), b, listIdx, labels)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range plan.Entries() {
+		switch details := e.Details(); {
+		case e.Imported:
+			fmt.Printf("✓ %s (already imported)\n", e.Card.Name)
+		case details != "":
+			fmt.Printf("+ %s (%s)\n", e.Card.Name, details)
+		default:
+			fmt.Printf("+ %s\n", e.Card.Name)
+		}
+	}
+	if *dryRun {
+		fmt.Printf("%d cards to import into %s\n", plan.Pending(), plan.ListName)
+		return nil
+	}
+	var created int
+	err = plan.Run(func(c domain.Card) (domain.Card, error) {
+		return source.CreateCard(plan.BoardID, plan.ListID, c)
+	}, func(domain.Card) { created++ })
+	if err != nil {
+		return errors.Wrapf(err, "import interrupted after %d cards, run it again to resume", created)
+	}
+	fmt.Printf("%d cards imported into %s\n", created, plan.ListName)
+	if missing := plan.Missing(); missing > 0 {
+		fmt.Printf("%d labels or members were not found on the board and were left out\n", missing)
+	}
+	return nil
+}
@@ -0,0 +1,418 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/giannimassi/trello-tui/pkg/domain"
+	"github.com/giannimassi/trello-tui/pkg/export"
+	"github.com/giannimassi/trello-tui/pkg/importer"
+	"github.com/giannimassi/trello-tui/pkg/state"
+)
+
+// dateLayout is the layout of due dates printed by the subcommands
+const dateLayout = "2006-01-02 15:04"
+
+// jsonBoard and jsonList are the JSON representations of boards and lists
+type jsonBoard struct {
+	Name string `json:"name"`
+}
+
+type jsonList struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Cards int    `json:"cards"`
+}
+
+// listItem and cardItem are the lists and cards the output templates are executed with
+type listItem struct {
+	domain.List
+	Cards []domain.Card // cards of the list, in order
+}
+
+type cardItem struct {
+	domain.Card
+	List string // name of the list containing the card
+}
+
+// Output presets of the subcommands
+var (
+	boardPresets = presets{
+		fields:  "Boards have the fields of domain.Board: .ID, .Name, .Description and .Lists, loaded only for custom templates",
+		table:   "{{.Name}}",
+		oneline: "{{.Name}}",
+	}
+	listPresets = presets{
+		fields:  "Lists have the fields of domain.List: .ID, .Name and .Pos, plus .Cards, their cards in order",
+		header:  "NAME\tCARDS",
+		table:   "{{.Name}}\t{{len .Cards}}",
+		oneline: "{{.Name}} ({{len .Cards}})",
+	}
+	cardPresets = presets{
+		fields: "Cards have the fields of domain.Card: .ID, .IdShort, .Name, .Description, .Labels, .Due, .Members, " +
+			".Checklists, .Comments and .URL, plus .List, the name of their list",
+		header:  "NUMBER\tLIST\tTITLE\tLABELS\tDUE",
+		table:   "#{{.IdShort}}\t{{.List}}\t{{.Name}}\t{{join (labels .Labels) \", \"}}\t{{date .Due}}",
+		oneline: "#{{.IdShort}} {{.Name}}{{range labels .Labels}} #{{.}}{{end}}",
+	}
+)
+
+// runBoards prints the names of the boards
+func runBoards(args []string) error {
+	fs := newFlagSet("boards", "[-json|-format fmt]")
+	sf := addSourceFlags(fs)
+	out := addOutputFlags(fs, boardPresets)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	source, cleanup, err := sf.source()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	names, err := source.Boards()
+	if err != nil {
+		return err
+	}
+	boards := make([]jsonBoard, len(names))
+	items := make([]interface{}, len(names))
+	for i, name := range names {
+		boards[i] = jsonBoard{Name: name}
+		items[i] = &domain.Board{Name: name}
+		if !out.templated() {
+			continue
+		}
+		// boards are only loaded when their content may be needed by the template
+		if items[i], err = source.Board(name); err != nil {
+			return errors.Wrapf(err, "could not load board %q", name)
+		}
+	}
+	return out.print(boards, items, nil)
+}
+
+// runLists prints the lists of a board
+func runLists(args []string) error {
+	fs := newFlagSet("lists", "-board <name> [-json|-format fmt]")
+	sf := addSourceFlags(fs)
+	out := addOutputFlags(fs, listPresets)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	_, b, cleanup, err := sf.loadBoard()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	lists := make([]jsonList, len(b.Lists))
+	items := make([]interface{}, len(b.Lists))
+	for i, l := range b.Lists {
+		lists[i] = jsonList{ID: l.ID, Name: l.Name, Cards: len(l.CartIds)}
+		cards := make([]domain.Card, len(l.CartIds))
+		for j, id := range l.CartIds {
+			cards[j] = l.CardsByID[id]
+		}
+		items[i] = listItem{List: l, Cards: cards}
+	}
+	return out.print(lists, items, nil)
+}
+
+// runCards prints the cards of a board, optionally only the ones of a list or with a label
+func runCards(args []string) error {
+	fs := newFlagSet("cards", "-board <name> [-list <name>] [-label <name>] [-json|-format fmt]")
+	sf := addSourceFlags(fs)
+	out := addOutputFlags(fs, cardPresets)
+	listName := fs.String("list", "", "only print the cards of the list with this name")
+	label := fs.String("label", "", "only print the cards with the label with this name, or colour if unnamed")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	_, b, cleanup, err := sf.loadBoard()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if *listName != "" {
+		if _, err := findList(b, *listName); err != nil {
+			return err
+		}
+	}
+
+	cards := []export.Card{}
+	var items []interface{}
+	for _, l := range b.Lists {
+		if *listName != "" && !strings.EqualFold(l.Name, *listName) {
+			continue
+		}
+		for _, id := range l.CartIds {
+			c := l.CardsByID[id]
+			if matched, _ := domain.MatchLabels([]string{*label}, c.Labels); *label == "" || len(matched) > 0 {
+				cards = append(cards, export.NewCard(c, l.Name))
+				items = append(items, cardItem{Card: c, List: l.Name})
+			}
+		}
+	}
+	return out.print(cards, items, nil)
+}
+
+// cardSubcommands are the subcommands of the card subcommand, by name
+var cardSubcommands = map[string]func(args []string) error{
+	"show":    runCardShow,
+	"add":     runCardAdd,
+	"move":    runCardMove,
+	"comment": runCardComment,
+}
+
+// runCard runs the card subcommand named by the first argument
+func runCard(args []string) error {
+	names := make([]string, 0, len(cardSubcommands))
+	for name := range cardSubcommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "--help" {
+		fmt.Fprintf(os.Stderr, "Usage: trello-tui card <%s> ...\n", strings.Join(names, "|"))
+		return flag.ErrHelp
+	}
+	run, found := cardSubcommands[args[0]]
+	if !found {
+		return errors.Errorf("unknown card subcommand %q (available: %s)", args[0], strings.Join(names, ", "))
+	}
+	return run(args[1:])
+}
+
+// runCardShow prints the details of a card
+func runCardShow(args []string) error {
+	fs := newFlagSet("card show", "-board <name> [-json|-format fmt] <number|id>")
+	sf := addSourceFlags(fs)
+	out := addOutputFlags(fs, cardPresets)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("expected the number or id of the card")
+	}
+	_, b, cleanup, err := sf.loadBoard()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	c, listIdx, err := findCard(b, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	list := b.Lists[listIdx].Name
+	card := export.NewCard(c, list)
+	return out.print(card, []interface{}{cardItem{Card: c, List: list}}, func(w io.Writer) {
+		fmt.Fprintf(w, "#%d %s\n", card.Number, card.Title)
+		fmt.Fprintf(w, "List:\t%s\n", card.List)
+		if len(card.Labels) > 0 {
+			fmt.Fprintf(w, "Labels:\t%s\n", strings.Join(card.Labels, ", "))
+		}
+		if len(card.Members) > 0 {
+			fmt.Fprintf(w, "Members:\t%s\n", strings.Join(card.Members, ", "))
+		}
+		if card.Due != nil {
+			complete := ""
+			if card.DueComplete {
+				complete = " (complete)"
+			}
+			fmt.Fprintf(w, "Due:\t%s%s\n", card.Due.Format(dateLayout), complete)
+		}
+		if card.URL != "" {
+			fmt.Fprintf(w, "URL:\t%s\n", card.URL)
+		}
+		if card.Description != "" {
+			fmt.Fprintf(w, "\n%s\n", card.Description)
+		}
+		for _, cl := range c.Checklists {
+			fmt.Fprintf(w, "\n%s (%d/%d)\n", cl.Name, cl.Completed(), len(cl.Items))
+			for _, item := range cl.Items {
+				box := " "
+				if item.Complete {
+					box = "x"
+				}
+				fmt.Fprintf(w, "  [%s] %s\n", box, item.Name)
+			}
+		}
+	})
+}
+
+// runCardAdd creates a card at the bottom of a list
+func runCardAdd(args []string) error {
+	fs := newFlagSet("card add", "-board <name> -list <name> [-description text] [-labels a,b] [-members a,b] [-due date] [-json|-format fmt] <title>")
+	sf := addSourceFlags(fs)
+	out := addOutputFlags(fs, cardPresets)
+	listName := fs.String("list", "", "name of the list the card is added to")
+	description := fs.String("description", "", "description of the card")
+	labels := fs.String("labels", "", "comma separated names of the labels of the card")
+	members := fs.String("members", "", "comma separated full names or usernames of the members of the card")
+	dueInput := fs.String("due", "", "due date, e.g. `2020-03-02 17:00`, `tomorrow 17:00` or `+3d`")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	title := strings.TrimSpace(strings.Join(fs.Args(), " "))
+	if title == "" {
+		fs.Usage()
+		return errors.New("expected the title of the card")
+	}
+	card := domain.NewCard("", 0, title, *description, 0, nil, nil)
+	card.Members = importer.Split(*members)
+	if *dueInput != "" {
+		due, err := domain.ParseDue(*dueInput, time.Now())
+		if err != nil {
+			return errors.Wrapf(err, "invalid due date %q", *dueInput)
+		}
+		card.Due = &domain.Due{Time: due}
+	}
+
+	source, b, cleanup, err := sf.loadBoard()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	listIdx, err := findList(b, *listName)
+	if err != nil {
+		return err
+	}
+	if names := importer.Split(*labels); len(names) > 0 {
+		available, err := source.Labels(b.ID)
+		if err != nil {
+			return errors.Wrapf(err, "could not load the labels of board %q", b.Name)
+		}
+		var unknown []string
+		card.Labels, unknown = domain.MatchLabels(names, available)
+		if len(unknown) > 0 {
+			return errors.Errorf("unknown labels: %s", strings.Join(unknown, ", "))
+		}
+	}
+
+	l := b.Lists[listIdx]
+	created, err := source.CreateCard(b.ID, l.ID, card)
+	if err != nil {
+		return err
+	}
+	return printCard(out, created, l.Name)
+}
+
+// runCardMove moves a card to the bottom, or the top, of a list
+func runCardMove(args []string) error {
+	fs := newFlagSet("card move", "-board <name> -list <name> [-top] [-json|-format fmt] <number|id>")
+	sf := addSourceFlags(fs)
+	out := addOutputFlags(fs, cardPresets)
+	listName := fs.String("list", "", "name of the list the card is moved to")
+	top := fs.Bool("top", false, "move the card to the top of the list instead of the bottom")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("expected the number or id of the card")
+	}
+	source, b, cleanup, err := sf.loadBoard()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	c, _, err := findCard(b, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	listIdx, err := findList(b, *listName)
+	if err != nil {
+		return err
+	}
+
+	l := b.Lists[listIdx]
+	cardIdx := len(l.CartIds)
+	if *top {
+		cardIdx = 0
+	}
+	newBoard, _ := b.WithCardMoved(c.ID, listIdx, cardIdx)
+	moved, _ := newBoard.CardByID(c.ID)
+	if err := source.MoveCard(c.ID, l.ID, moved.Pos); err != nil {
+		return err
+	}
+	return printCard(out, moved, l.Name)
+}
+
+// runCardComment adds a comment to a card, the text is read from stdin if it is `-`
+func runCardComment(args []string) error {
+	fs := newFlagSet("card comment", "-board <name> <number|id> <text|->")
+	sf := addSourceFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() < 2 {
+		fs.Usage()
+		return errors.New("expected the number or id of the card and the text of the comment")
+	}
+	text := strings.Join(fs.Args()[1:], " ")
+	if text == "-" {
+		data, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return errors.Wrap(err, "could not read the comment")
+		}
+		text = string(data)
+	}
+	if text = strings.TrimSpace(text); text == "" {
+		return errors.New("the comment is empty")
+	}
+
+	source, b, cleanup, err := sf.loadBoard()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	commenter, ok := source.(state.CommentSource)
+	if !ok {
+		return errors.New("comments are not supported by board files")
+	}
+	c, _, err := findCard(b, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return commenter.AddComment(c.ID, text)
+}
+
+// printCard prints the card in the list with the provided name, with the oneline preset by default
+func printCard(out *outputFlags, c domain.Card, list string) error {
+	if *out.format == "" {
+		*out.format = onelinePreset
+	}
+	return out.print(export.NewCard(c, list), []interface{}{cardItem{Card: c, List: list}}, nil)
+}
+
+// findList returns the index of the list with the provided name, ignoring case
+func findList(b *domain.Board, name string) (int, error) {
+	if name == "" {
+		return -1, errors.New("the -list flag is required")
+	}
+	for i, l := range b.Lists {
+		if strings.EqualFold(l.Name, name) {
+			return i, nil
+		}
+	}
+	return -1, errors.Errorf("list %q not found in board %q", name, b.Name)
+}
+
+// findCard returns the card referred to by its number, optionally prefixed by `#`, or by its id,
+// and the index of the list containing it
+func findCard(b *domain.Board, ref string) (domain.Card, int, error) {
+	for i, l := range b.Lists {
+		for _, c := range l.CardsByID {
+			if c.ID == ref || (c.IdShort != 0 && fmt.Sprintf("#%d", c.IdShort) == "#"+strings.TrimPrefix(ref, "#")) {
+				return c, i, nil
+			}
+		}
+	}
+	return domain.Card{}, -1, errors.Errorf("card %s not found in board %q", ref, b.Name)
+}
@@ -11,4 +11,5 @@ require (
 	github.com/rivo/tview v0.0.0-20191129065140-82b05c9fb329
 	github.com/rs/zerolog v1.17.2
 	golang.org/x/sys v0.0.0-20191210023423-ac6580df4449 // indirect
+	gopkg.in/yaml.v2 v2.2.8
 )
@@ -7,15 +7,18 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 
 	"github.com/giannimassi/trello-tui/pkg/app"
+	"github.com/giannimassi/trello-tui/pkg/config"
 	"github.com/giannimassi/trello-tui/pkg/gui"
 	"github.com/giannimassi/trello-tui/pkg/state"
 	"github.com/giannimassi/trello-tui/pkg/trello"
+	"github.com/giannimassi/trello-tui/pkg/trello/fake"
 )
 
 var (
@@ -32,16 +35,40 @@ const (
 	// TrelloToken is a the environment variable for storing the trello token
 	TrelloToken = "TRELLO_TOKEN"
 
-	minRefreshInterval     = time.Second * 1
-	defaultRefreshInterval = time.Second * 10
+	minRefreshInterval        = time.Second * 1
+	defaultRefreshInterval    = time.Second * 10
+	defaultMaxRefreshInterval = time.Minute * 5
+
+	minColumnWidth        = 10
+	defaultMinColumnWidth = 30
+
+	// demoActivityInterval is the interval at which the cards of the demo board are modified
+	demoActivityInterval = 30 * time.Second
+
+	// trelloTimeout is the timeout of the requests to trello
+	trelloTimeout = time.Second * 10
 )
 
 // setup parses the configuration from flags and envronment and setups the global logger
 func setup() (app.Config, func()) {
 	boardName := flag.String("board", "", "board name")
 	refresh := flag.Duration("refresh", defaultRefreshInterval, fmt.Sprintf("refresh interval (min=%v)", minRefreshInterval))
+	adaptiveRefresh := flag.Bool("adaptive-refresh", false, "refresh less often while idle or offline")
+	maxRefresh := flag.Duration("max-refresh", defaultMaxRefreshInterval, "maximum refresh interval used by adaptive refresh")
+	columnWidth := flag.Int("column-width", defaultMinColumnWidth, fmt.Sprintf("minimum width of each list column (min=%v)", minColumnWidth))
+	configPath := flag.String("config", config.DefaultPath(), "configuration file path")
+	keymap := flag.String("keymap", "", fmt.Sprintf("key bindings preset (%s)", strings.Join(gui.KeymapPresets(), ", ")))
+	theme := flag.String("theme", "", fmt.Sprintf("colour theme (%s)", strings.Join(gui.Themes(), ", ")))
+	notifyCommand := flag.String("notify-command", "", "shell command run for each notification, with the event as JSON on stdin")
+	demo := flag.Bool("demo", false, "run against a demo board served locally, no trello account required")
+	boardFile := flag.String("file", "", "board file (.json, .yaml, .md) or directory of board files used instead of trello")
 	logFlag := flag.Bool("log", false, "Log to file")
 	v := flag.Bool("vv", false, "Increase verbosity level")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: trello-tui [flags]\n       trello-tui <subcommand> [flags]\n\nFlags:\n")
+		flag.PrintDefaults()
+		fmt.Fprintf(flag.CommandLine.Output(), "\n%s", subcommandsUsage())
+	}
 	flag.Parse()
 
 	cleanup := func() {}
@@ -67,26 +94,87 @@ func setup() (app.Config, func()) {
 		log.Warn().Msg("Minimum value for refresh interval is 10 s")
 		*refresh = minRefreshInterval
 	}
+	if *maxRefresh < *refresh {
+		*maxRefresh = *refresh
+	}
+
+	file, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if *keymap != "" {
+		file.Keymap = *keymap
+	}
+	if *theme != "" {
+		file.Theme = *theme
+	}
+	if *notifyCommand != "" {
+		file.NotifyCommand = *notifyCommand
+	}
+
+	if *columnWidth < minColumnWidth {
+		log.Warn().Int("min", minColumnWidth).Msg("Column width is less than the minimum value")
+		*columnWidth = minColumnWidth
+	}
+
+	trelloCfg := *trelloConfig()
+	if *demo {
+		server := fake.NewDemoServer()
+		stopActivity := server.Simulate(demoActivityInterval)
+		trelloCfg = trello.Config{
+			User:    fake.DemoUser,
+			Key:     "demo",
+			Token:   "demo",
+			Timeout: trelloTimeout,
+			BaseURL: server.APIURL(),
+		}
+		if *boardName == "" {
+			*boardName = fake.DemoBoard
+		}
+		logCleanup := cleanup
+		cleanup = func() {
+			stopActivity()
+			server.Close()
+			logCleanup()
+		}
+	}
+
+	var source state.BoardSource
+	if *boardFile != "" {
+		if *demo {
+			fmt.Fprintln(os.Stderr, "the -file and -demo flags cannot be used together")
+			os.Exit(1)
+		}
+		source = fileSource(*boardFile)
+	}
 
 	return app.Config{
 		State: state.Config{
-			Trello: trello.Config{
-				User:    os.Getenv(TrelloUser),
-				Key:     os.Getenv(TrelloKey),
-				Token:   os.Getenv(TrelloToken),
-				Timeout: time.Second * 10,
-			},
+			Trello:               trelloCfg,
+			Source:               source,
 			SelectedBoard:        *boardName,
 			BoardRefreshInterval: *refresh,
+			AdaptiveRefresh:      *adaptiveRefresh,
+			MaxRefreshInterval:   *maxRefresh,
+			NotifyCommand:        file.NotifyCommand,
 		},
 
 		Gui: gui.Config{
-			Dev: *v,
+			Dev:            *v,
+			MinColumnWidth: *columnWidth,
+			Keymap:         file.Keymap,
+			Keys:           file.Keys,
+			Theme:          file.Theme,
+			CopyFormat:     file.CopyFormat,
 		},
 	}, cleanup
 }
 
 func main() {
+	if runSubcommand(os.Args[1:]) {
+		return
+	}
 	var (
 		cfg, cleanup = setup()
 		a            = app.NewApp(&cfg)
@@ -99,6 +187,7 @@ func main() {
 	log.Info().Str("version", version).Str("commit", commit).Str("build", date).Msg("Starting trello-tui")
 	if err := a.Init(); err != nil {
 		log.Error().Err(err).Msg("Unexpected error while initializing. Stopping application")
+		fmt.Fprintln(os.Stderr, err)
 		return
 	}
 
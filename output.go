@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/pkg/errors"
+
+	"github.com/giannimassi/trello-tui/pkg/domain"
+)
+
+// Output presets available in addition to json and custom templates
+const (
+	tablePreset   = "table"
+	onelinePreset = "oneline"
+	jsonPreset    = "json"
+)
+
+// presets are the templates of the output presets of a subcommand, executed for each item
+type presets struct {
+	fields  string // fields of the items available to the templates, for the usage
+	header  string // header of the table preset, columns are separated by tabs
+	table   string // row of the table preset, columns are separated by tabs
+	oneline string
+}
+
+// templateFuncs are the functions available to the output templates in addition to the builtin ones
+var templateFuncs = template.FuncMap{
+	"join":   strings.Join,
+	"labels": domain.LabelNames,
+	"date": func(due *domain.Due) string {
+		if due == nil {
+			return ""
+		}
+		return due.Time.Format(dateLayout)
+	},
+}
+
+// outputFlags are the flags selecting how the subcommands print their results
+type outputFlags struct {
+	json    *bool
+	format  *string
+	presets presets
+}
+
+// addOutputFlags registers the flags selecting the output of items with the provided presets
+func addOutputFlags(fs *flag.FlagSet, p presets) *outputFlags {
+	return &outputFlags{
+		json: fs.Bool("json", false, "print the result as JSON"),
+		format: fs.String("format", "", "print the result with a preset (table, oneline, json) or a Go template executed for each item, "+
+			"with the join, labels and date functions, e.g. '"+p.oneline+"'\n"+p.fields),
+		presets: p,
+	}
+}
+
+// templated returns true if the items are printed with a custom template
+func (f *outputFlags) templated() bool {
+	switch *f.format {
+	case "", tablePreset, onelinePreset, jsonPreset:
+		return false
+	}
+	return !*f.json
+}
+
+// print writes value as JSON, or executes the template selected by the flags for each of the
+// items, by default it calls text for writing the result, or uses the table preset if text is nil
+func (f *outputFlags) print(value interface{}, items []interface{}, text func(w io.Writer)) error {
+	format := *f.format
+	if *f.json {
+		if format != "" && format != jsonPreset {
+			return errors.New("the -json and -format flags cannot be used together")
+		}
+		format = jsonPreset
+	}
+
+	p := f.presets
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	switch format {
+	case jsonPreset:
+		e := json.NewEncoder(os.Stdout)
+		e.SetIndent("", "  ")
+		return e.Encode(value)
+	case "":
+		if text != nil {
+			text(tw)
+			return tw.Flush()
+		}
+		fallthrough
+	case tablePreset:
+		if p.header != "" {
+			if _, err := io.WriteString(tw, p.header+"\n"); err != nil {
+				return err
+			}
+		}
+		if err := execute(tw, p.table, items); err != nil {
+			return err
+		}
+		return tw.Flush()
+	case onelinePreset:
+		return execute(os.Stdout, p.oneline, items)
+	}
+	return execute(os.Stdout, format, items)
+}
+
+// execute executes the template for each of the items, ending each output with a newline
+func execute(w io.Writer, text string, items []interface{}) error {
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	t, err := template.New("format").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return errors.Wrap(err, "invalid format")
+	}
+	for _, item := range items {
+		if err := t.Execute(w, item); err != nil {
+			return errors.Wrap(err, "invalid format")
+		}
+	}
+	return nil
+}
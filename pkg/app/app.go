@@ -40,7 +40,7 @@ func NewApp(cfg *Config) *App {
 func (a *App) Init() error {
 	storeState, getState := store.NewStore(a.gui.Sync)
 	a.updater = state.NewUpdater(&a.cfg.State, storeState)
-	if err := a.gui.Init(getState); err != nil {
+	if err := a.gui.Init(getState, a.updater); err != nil {
 		a.l.Error().Err(err).Msg("Unexpected error while initializing gui")
 		return err
 	}
@@ -0,0 +1,311 @@
+// Package boardfile stores boards in local JSON or YAML files, e.g. a kanban board tracked in a
+// git repository, as an alternative to trello
+package boardfile
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+
+	"github.com/giannimassi/trello-tui/pkg/domain"
+)
+
+// Board is the content of a board file
+type Board struct {
+	Name        string            `json:"name" yaml:"name"`
+	Description string            `json:"description,omitempty" yaml:"description,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"` // colour of the labels, by name
+	Lists       []*List           `json:"lists" yaml:"lists"`
+}
+
+// List is a list of a board file, the order of the lists and cards in the file is their order
+// on the board, lists without an id are assigned one when the file is loaded
+type List struct {
+	ID       string  `json:"id,omitempty" yaml:"id,omitempty"`
+	Name     string  `json:"name" yaml:"name"`
+	Archived bool    `json:"archived,omitempty" yaml:"archived,omitempty"` // archived lists are kept in the file but not shown
+	Cards    []*Card `json:"cards" yaml:"cards"`
+
+	pos float64
+}
+
+// Card is a card of a board file, cards without a number are numbered when the file is loaded
+type Card struct {
+	Number      int          `json:"number,omitempty" yaml:"number,omitempty"`
+	Name        string       `json:"name" yaml:"name"`
+	Description string       `json:"description,omitempty" yaml:"description,omitempty"`
+	Labels      []string     `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Due         string       `json:"due,omitempty" yaml:"due,omitempty"` // RFC 3339 date and time
+	DueComplete bool         `json:"due_complete,omitempty" yaml:"due_complete,omitempty"`
+	Members     []string     `json:"members,omitempty" yaml:"members,omitempty"`
+	Checklists  []*Checklist `json:"checklists,omitempty" yaml:"checklists,omitempty"`
+
+	pos float64
+}
+
+// Checklist is a checklist of a card of a board file
+type Checklist struct {
+	Name  string           `json:"name" yaml:"name"`
+	Items []*ChecklistItem `json:"items" yaml:"items"`
+}
+
+// ChecklistItem is an item of a checklist of a board file
+type ChecklistItem struct {
+	Name     string `json:"name" yaml:"name"`
+	Complete bool   `json:"complete,omitempty" yaml:"complete,omitempty"`
+}
+
+// format encodes and decodes board files
+type format struct {
+	unmarshal func(data []byte, v interface{}) error
+	marshal   func(v interface{}) ([]byte, error)
+}
+
+// formats are the supported file formats, by extension
+var formats = map[string]format{
+	".json": {unmarshal: json.Unmarshal, marshal: func(v interface{}) ([]byte, error) {
+		data, err := json.MarshalIndent(v, "", "  ")
+		return append(data, '\n'), err
+	}},
+	".yaml": {unmarshal: yaml.Unmarshal, marshal: yaml.Marshal},
+	".yml":  {unmarshal: yaml.Unmarshal, marshal: yaml.Marshal},
+}
+
+// isBoardFile returns true if the file at the provided path has the extension of a supported format
+func isBoardFile(path string) bool {
+	_, found := formats[strings.ToLower(filepath.Ext(path))]
+	return found
+}
+
+// readBoard reads and decodes the board file at the provided path, ids and positions are
+// assigned to the lists and cards
+func readBoard(path string) (*Board, error) {
+	f, found := formats[strings.ToLower(filepath.Ext(path))]
+	if !found {
+		return nil, errors.Errorf("unsupported board file format %s", path)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read board file %s", path)
+	}
+	var b Board
+	if err := f.unmarshal(data, &b); err != nil {
+		return nil, errors.Wrapf(err, "could not parse board file %s", path)
+	}
+	if b.Name == "" {
+		b.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	b.index()
+	return &b, nil
+}
+
+// writeBoard encodes the board and replaces the file at the provided path
+func writeBoard(path string, b *Board) error {
+	f, found := formats[strings.ToLower(filepath.Ext(path))]
+	if !found {
+		return errors.Errorf("unsupported board file format %s", path)
+	}
+	data, err := f.marshal(b)
+	if err != nil {
+		return errors.Wrapf(err, "could not encode board %s", b.Name)
+	}
+	// the file is replaced atomically, so that it is never read partially written
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path))
+	if err != nil {
+		return errors.Wrapf(err, "could not write board file %s", path)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return errors.Wrapf(err, "could not write board file %s", path)
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrapf(err, "could not write board file %s", path)
+	}
+	if info, err := os.Stat(path); err == nil {
+		_ = os.Chmod(tmp.Name(), info.Mode())
+	}
+	return errors.Wrapf(os.Rename(tmp.Name(), path), "could not write board file %s", path)
+}
+
+// index assigns the positions of the lists and cards, the ids of the lists without one and
+// numbers the cards without one. Lists and cards duplicating the id or number of a previous one,
+// e.g. copied in the file by hand, are assigned a new one, so that ids are unique
+func (b *Board) index() {
+	var maxNumber int
+	listIDs := make(map[string]bool, len(b.Lists))
+	numbers := make(map[int]bool)
+	for _, l := range b.Lists {
+		if listIDs[l.ID] {
+			l.ID = ""
+		}
+		listIDs[l.ID] = true
+		for _, c := range l.Cards {
+			if numbers[c.Number] || c.Number < 0 {
+				c.Number = 0
+			}
+			numbers[c.Number] = true
+			if c.Number > maxNumber {
+				maxNumber = c.Number
+			}
+		}
+	}
+	nextListID := 1
+	for i, l := range b.Lists {
+		for l.ID == "" {
+			if id := listID(nextListID); !listIDs[id] {
+				l.ID = id
+				listIDs[id] = true
+			}
+			nextListID++
+		}
+		l.pos = float64(i+1) * domain.PosStep
+		for j, c := range l.Cards {
+			if c.Number == 0 {
+				maxNumber++
+				c.Number = maxNumber
+			}
+			c.pos = float64(j+1) * domain.PosStep
+		}
+	}
+}
+
+// sort orders the lists and cards by position
+func (b *Board) sort() {
+	sort.SliceStable(b.Lists, func(i, j int) bool { return b.Lists[i].pos < b.Lists[j].pos })
+	for _, l := range b.Lists {
+		cards := l.Cards
+		sort.SliceStable(cards, func(i, j int) bool { return cards[i].pos < cards[j].pos })
+	}
+}
+
+// list returns the list with the corresponding id
+func (b *Board) list(id string) (*List, int) {
+	for i, l := range b.Lists {
+		if l.ID == id {
+			return l, i
+		}
+	}
+	return nil, -1
+}
+
+// card returns the card with the corresponding id and the list containing it
+func (b *Board) card(id string) (*Card, *List) {
+	for _, l := range b.Lists {
+		for _, c := range l.Cards {
+			if cardID(c.Number) == id {
+				return c, l
+			}
+		}
+	}
+	return nil, nil
+}
+
+// domain returns the board as a domain.Board, path is used as the id of the board
+func (b *Board) domain(path string) *domain.Board {
+	lists := make([]domain.List, 0, len(b.Lists))
+	var isEmpty = true
+	for _, l := range b.Lists {
+		if l.Archived {
+			continue
+		}
+		cards := make(map[string]domain.Card, len(l.Cards))
+		for _, c := range l.Cards {
+			cards[cardID(c.Number)] = b.domainCard(c)
+			isEmpty = false
+		}
+		lists = append(lists, domain.NewList(l.ID, l.Name, l.pos, cards))
+	}
+	return domain.NewBoard(path, b.Name, b.Description, lists, isEmpty)
+}
+
+// domainCard returns the card as a domain.Card
+func (b *Board) domainCard(c *Card) domain.Card {
+	labels := make([]domain.CardLabel, len(c.Labels))
+	for j, name := range c.Labels {
+		labels[j] = domain.CardLabel{Name: name, Color: b.Labels[name]}
+	}
+	card := domain.NewCard(cardID(c.Number), c.Number, c.Name, c.Description, c.pos, labels, c.due())
+	card.Members = c.Members
+	card.Checklists = c.checklists()
+	return card
+}
+
+// labels returns the labels of the board, the ones with a colour followed by the ones only used
+// by the cards, sorted by name
+func (b *Board) labels() []domain.CardLabel {
+	var names []string
+	for name := range b.Labels {
+		names = append(names, name)
+	}
+	for _, l := range b.Lists {
+		for _, c := range l.Cards {
+			for _, name := range c.Labels {
+				if _, found := b.Labels[name]; !found && !contains(names, name) {
+					names = append(names, name)
+				}
+			}
+		}
+	}
+	sort.Strings(names)
+	labels := make([]domain.CardLabel, len(names))
+	for i, name := range names {
+		labels[i] = domain.CardLabel{Name: name, Color: b.Labels[name]}
+	}
+	return labels
+}
+
+// contains returns true if the value is one of the values
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// due returns the due date of the card, nil if it has none or it cannot be parsed
+func (c *Card) due() *domain.Due {
+	if c.Due == "" {
+		return nil
+	}
+	due, err := time.Parse(time.RFC3339, c.Due)
+	if err != nil {
+		return nil
+	}
+	return &domain.Due{Time: due.Local(), Complete: c.DueComplete}
+}
+
+// checklists returns the checklists of the card
+func (c *Card) checklists() []domain.Checklist {
+	if len(c.Checklists) == 0 {
+		return nil
+	}
+	checklists := make([]domain.Checklist, len(c.Checklists))
+	for i, cl := range c.Checklists {
+		checklists[i] = domain.Checklist{Name: cl.Name, Items: make([]domain.ChecklistItem, len(cl.Items))}
+		for j, item := range cl.Items {
+			checklists[i].Items[j] = domain.ChecklistItem{Name: item.Name, Complete: item.Complete}
+		}
+	}
+	return checklists
+}
+
+// listID returns the id of the list with the provided number
+func listID(number int) string {
+	return "list-" + strconv.Itoa(number)
+}
+
+// cardID returns the id of the card with the provided number
+func cardID(number int) string {
+	return "card-" + strconv.Itoa(number)
+}
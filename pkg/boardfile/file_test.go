@@ -0,0 +1,227 @@
+package boardfile
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/giannimassi/trello-tui/pkg/domain"
+)
+
+func TestMain(m *testing.M) {
+	time.Local = time.UTC
+	zerolog.SetGlobalLevel(zerolog.Disabled)
+	os.Exit(m.Run())
+}
+
+// tempCopy copies the file of the testdata directory to a temporary directory, which is removed
+// by the returned function
+func tempCopy(t *testing.T, name string) (string, func()) {
+	t.Helper()
+	data, err := ioutil.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "boardfile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return path, func() { os.RemoveAll(dir) }
+}
+
+func TestReadWriteRoundTrip(t *testing.T) {
+	for _, name := range []string{"board.json", "board.yaml"} {
+		t.Run(name, func(t *testing.T) {
+			path, remove := tempCopy(t, name)
+			defer remove()
+			b, err := readBoard(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := writeBoard(path, b); err != nil {
+				t.Fatal(err)
+			}
+			got, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := ioutil.ReadFile(filepath.Join("testdata", name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != string(want) {
+				t.Errorf("file =\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestReadFormats(t *testing.T) {
+	json, err := readBoard(filepath.Join("testdata", "board.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	yaml, err := readBoard(filepath.Join("testdata", "board.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changes := domain.Diff(json.domain(""), yaml.domain("")); len(changes) > 0 || !reflect.DeepEqual(json.Labels, yaml.Labels) {
+		t.Errorf("the JSON and YAML versions of the board differ: %+v", changes)
+	}
+
+	b := json.domain("board.json")
+	if len(b.Lists) != 2 || b.Lists[0].Name != "To Do" || b.Lists[1].Name != "Done" {
+		t.Fatalf("lists = %+v, want the lists not archived", b.Lists)
+	}
+	c, found := b.CardByID("card-1")
+	if !found {
+		t.Fatal("card-1 not found")
+	}
+	labels := []domain.CardLabel{{Name: "bug", Color: "red"}, {Name: "urgent"}}
+	if c.Name != "Fix login" || !reflect.DeepEqual(c.Labels, labels) || !reflect.DeepEqual(c.Members, []string{"Jane Doe"}) {
+		t.Errorf("card = %+v", c)
+	}
+	if want := time.Date(2030, time.January, 18, 17, 0, 0, 0, time.UTC); c.Due == nil || !c.Due.Time.Equal(want) || c.Due.Complete {
+		t.Errorf("due = %+v, want %v", c.Due, want)
+	}
+	c, _ = b.CardByID("card-3")
+	if len(c.Checklists) != 1 || len(c.Checklists[0].Items) != 2 || !c.Checklists[0].Items[0].Complete {
+		t.Errorf("checklists = %+v", c.Checklists)
+	}
+	if labels := json.labels(); len(labels) != 3 || labels[2].Name != "urgent" {
+		t.Errorf("labels = %+v", labels)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "boardfile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	invalid := filepath.Join(dir, "invalid.json")
+	if err := ioutil.WriteFile(invalid, []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{invalid, filepath.Join(dir, "missing.yaml"), filepath.Join(dir, "board.toml")} {
+		if _, err := readBoard(path); err == nil {
+			t.Errorf("no error reading %s", path)
+		}
+	}
+}
+
+func TestIndex(t *testing.T) {
+	b := &Board{Lists: []*List{
+		{ID: "list-2", Cards: []*Card{{Number: 4}, {}, {Number: 4}}},
+		{Cards: []*Card{{Number: -1}, {Number: 2}}},
+		{ID: "list-2", Cards: []*Card{{Number: 2}}},
+	}}
+	b.index()
+
+	var ids []string
+	for _, l := range b.Lists {
+		ids = append(ids, l.ID)
+	}
+	if !reflect.DeepEqual(ids, []string{"list-2", "list-1", "list-3"}) {
+		t.Errorf("list ids = %v", ids)
+	}
+	// cards without a number or duplicating the number of a previous card are numbered in order
+	var numbers []int
+	for _, l := range b.Lists {
+		for _, c := range l.Cards {
+			numbers = append(numbers, c.Number)
+		}
+	}
+	if !reflect.DeepEqual(numbers, []int{4, 5, 6, 7, 2, 8}) {
+		t.Errorf("card numbers = %v", numbers)
+	}
+	if b.Lists[1].pos != 2*domain.PosStep || b.Lists[0].Cards[2].pos != 3*domain.PosStep {
+		t.Errorf("positions not assigned")
+	}
+}
+
+func TestSourceUpdates(t *testing.T) {
+	for _, name := range []string{"board.json", "board.yaml"} {
+		t.Run(name, func(t *testing.T) {
+			path, remove := tempCopy(t, name)
+			defer remove()
+			s := NewSource(path)
+			if err := s.Init(); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := s.Board(""); err != nil {
+				t.Fatal(err)
+			}
+
+			due := time.Date(2030, time.February, 1, 9, 0, 0, 0, time.UTC)
+			check := func(err error) {
+				t.Helper()
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			check(s.MoveCard("card-1", "list-2", 0))
+			check(s.SetCardDue("card-3", &due))
+			check(s.SetCardDue("card-2", nil))
+			check(s.SetCardDueComplete("card-1", true))
+			check(s.RenameList("list-1", "Next"))
+			l, err := s.CreateList(path, "Review", 1.5*domain.PosStep)
+			check(err)
+			if l.ID != "list-4" {
+				t.Errorf("list id = %s, want list-4", l.ID)
+			}
+			check(s.MoveList("list-2", 0))
+			check(s.ArchiveList("list-1"))
+			c, err := s.CreateCard(path, l.ID, domain.Card{Name: "Review the docs", Labels: []domain.CardLabel{{Name: "docs"}}, Members: []string{"Jane Doe"}})
+			check(err)
+			if c.ID != "card-4" || c.IdShort != 4 || len(c.Labels) != 1 || c.Labels[0].Color != "blue" {
+				t.Errorf("created card = %+v", c)
+			}
+			if err := s.MoveCard("card-9", "list-2", 0); err != ErrCardNotFound {
+				t.Errorf("MoveCard() = %v for an unknown card, want ErrCardNotFound", err)
+			}
+			if err := s.RenameList("list-9", "Unknown"); err != ErrListNotFound {
+				t.Errorf("RenameList() = %v for an unknown list, want ErrListNotFound", err)
+			}
+
+			b, err := s.Board("Release")
+			check(err)
+			var lists []string
+			for _, l := range b.Lists {
+				var cards []string
+				for _, id := range l.CartIds {
+					cards = append(cards, l.CardsByID[id].Name)
+				}
+				lists = append(lists, l.Name+": "+strings.Join(cards, ", "))
+			}
+			want := []string{"Done: Fix login, Update dependencies", "Review: Review the docs"}
+			if !reflect.DeepEqual(lists, want) {
+				t.Errorf("lists = %q, want %q", lists, want)
+			}
+			login, _ := b.CardByID("card-1")
+			updated, _ := b.CardByID("card-2")
+			if login.Due == nil || !login.Due.Complete || updated.Due != nil {
+				t.Errorf("due dates = %+v, %+v", login.Due, updated.Due)
+			}
+
+			// the archived list is kept in the file along with its cards
+			f, err := readBoard(path)
+			check(err)
+			archived, _ := f.list("list-1")
+			if archived == nil || !archived.Archived || archived.Name != "Next" || len(archived.Cards) != 1 || archived.Cards[0].Due != due.Format(time.RFC3339) {
+				t.Errorf("archived list = %+v", archived)
+			}
+		})
+	}
+}
@@ -0,0 +1,282 @@
+package boardfile
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/giannimassi/trello-tui/pkg/domain"
+)
+
+var (
+	// ErrBoardNotFound is returned when a board is not found
+	ErrBoardNotFound = errors.New("board not found")
+	// ErrListNotFound is returned when a change is requested on a list which is not part of the board
+	ErrListNotFound = errors.New("list not found")
+	// ErrCardNotFound is returned when a change is requested on a card which is not part of the board
+	ErrCardNotFound = errors.New("card not found")
+)
+
+// Source loads boards from a board file or from the board files in a directory, and writes the
+// changes back to the files
+type Source struct {
+	l    zerolog.Logger
+	path string
+
+	m       sync.Mutex
+	current string // path of the board file last loaded, which the changes are applied to
+}
+
+// NewSource returns a new instance of Source for the file or directory at the provided path
+func NewSource(path string) *Source {
+	return &Source{
+		l:    log.With().Str("m", "boardfile").Str("path", path).Logger(),
+		path: path,
+	}
+}
+
+// Init checks that the file or directory exists
+func (s *Source) Init() error {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return errors.Wrapf(err, "could not open %s", s.path)
+	}
+	if !info.IsDir() && !isBoardFile(s.path) {
+		return errors.Errorf("unsupported board file format %s, expected .json, .yaml or .yml", s.path)
+	}
+	s.l.Info().Msg("Initialized")
+	return nil
+}
+
+// Board returns the board with the provided name, when the source is a single file its board is
+// returned regardless of the name if the name is empty
+func (s *Source) Board(name string) (*domain.Board, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	paths, err := s.files()
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		b, err := readBoard(path)
+		if err != nil {
+			return nil, err
+		}
+		if strings.EqualFold(b.Name, name) || (name == "" && len(paths) == 1) {
+			s.current = path
+			return b.domain(path), nil
+		}
+	}
+	return nil, ErrBoardNotFound
+}
+
+// Boards returns the names of the boards of the board files
+func (s *Source) Boards() ([]string, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	paths, err := s.files()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(paths))
+	for _, path := range paths {
+		b, err := readBoard(path)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, b.Name)
+	}
+	return names, nil
+}
+
+// Labels returns the labels of the board of the board file at the path used as board id
+func (s *Source) Labels(boardID string) ([]domain.CardLabel, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	b, err := readBoard(boardID)
+	if err != nil {
+		return nil, err
+	}
+	return b.labels(), nil
+}
+
+// files returns the paths of the board files, sorted by name
+func (s *Source) files() ([]string, error) {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not open %s", s.path)
+	}
+	if !info.IsDir() {
+		return []string{s.path}, nil
+	}
+	entries, err := ioutil.ReadDir(s.path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read directory %s", s.path)
+	}
+	var paths []string
+	for _, e := range entries {
+		if !e.IsDir() && !strings.HasPrefix(e.Name(), ".") && isBoardFile(e.Name()) {
+			paths = append(paths, filepath.Join(s.path, e.Name()))
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// CreateList creates a new list with the provided name and position in the board with the corresponding id
+func (s *Source) CreateList(boardID, name string, pos float64) (domain.List, error) {
+	s.l.Debug().Str("name", name).Msg("Creating list")
+	var l *List
+	err := s.updateFile(boardID, func(b *Board) error {
+		l = &List{Name: name, Cards: []*Card{}, pos: pos}
+		b.Lists = append(b.Lists, l)
+		return nil
+	})
+	if err != nil {
+		return domain.List{}, err
+	}
+	return domain.NewList(l.ID, name, pos, map[string]domain.Card{}), nil
+}
+
+// RenameList sets the name of the list with the corresponding id
+func (s *Source) RenameList(listID, name string) error {
+	s.l.Debug().Str("list", listID).Str("name", name).Msg("Renaming list")
+	return s.updateList(listID, func(l *List) { l.Name = name })
+}
+
+// ArchiveList archives the list with the corresponding id
+func (s *Source) ArchiveList(listID string) error {
+	s.l.Debug().Str("list", listID).Msg("Archiving list")
+	return s.updateList(listID, func(l *List) { l.Archived = true })
+}
+
+// MoveList sets the position of the list with the corresponding id
+func (s *Source) MoveList(listID string, pos float64) error {
+	s.l.Debug().Str("list", listID).Float64("pos", pos).Msg("Moving list")
+	return s.updateList(listID, func(l *List) { l.pos = pos })
+}
+
+// SetCardDue sets the due date of the card with the corresponding id, a nil due removes the due date
+func (s *Source) SetCardDue(cardID string, due *time.Time) error {
+	s.l.Debug().Str("card", cardID).Msg("Setting card due date")
+	return s.updateCard(cardID, func(c *Card, _ *List, _ *Board) error {
+		c.Due = ""
+		if due != nil {
+			c.Due = due.Format(time.RFC3339)
+		}
+		return nil
+	})
+}
+
+// SetCardDueComplete marks the due date of the card with the corresponding id as complete or not
+func (s *Source) SetCardDueComplete(cardID string, complete bool) error {
+	s.l.Debug().Str("card", cardID).Bool("complete", complete).Msg("Setting card due date completion")
+	return s.updateCard(cardID, func(c *Card, _ *List, _ *Board) error {
+		c.DueComplete = complete
+		return nil
+	})
+}
+
+// MoveCard moves the card with the corresponding id to the list with the corresponding id, at the provided position
+func (s *Source) MoveCard(cardID, listID string, pos float64) error {
+	s.l.Debug().Str("card", cardID).Str("list", listID).Float64("pos", pos).Msg("Moving card")
+	return s.updateCard(cardID, func(c *Card, from *List, b *Board) error {
+		to, _ := b.list(listID)
+		if to == nil {
+			return ErrListNotFound
+		}
+		for i, other := range from.Cards {
+			if other == c {
+				from.Cards = append(from.Cards[:i], from.Cards[i+1:]...)
+				break
+			}
+		}
+		c.pos = pos
+		to.Cards = append(to.Cards, c)
+		return nil
+	})
+}
+
+// CreateCard adds the card at the bottom of the list with the corresponding id of the board file
+// at the path used as board id
+func (s *Source) CreateCard(boardID, listID string, c domain.Card) (domain.Card, error) {
+	s.l.Debug().Str("list", listID).Str("name", c.Name).Msg("Creating card")
+	card := &Card{Name: c.Name, Description: c.Description, Members: c.Members}
+	for _, l := range c.Labels {
+		card.Labels = append(card.Labels, l.Name)
+	}
+	if c.Due != nil {
+		card.Due = c.Due.Time.Format(time.RFC3339)
+		card.DueComplete = c.Due.Complete
+	}
+	var board *Board
+	err := s.updateFile(boardID, func(b *Board) error {
+		l, _ := b.list(listID)
+		if l == nil {
+			return ErrListNotFound
+		}
+		card.pos = float64(len(l.Cards)+1) * domain.PosStep
+		l.Cards = append(l.Cards, card)
+		board = b
+		return nil
+	})
+	if err != nil {
+		return domain.Card{}, err
+	}
+	return board.domainCard(card), nil
+}
+
+// updateList applies the update to the list with the corresponding id of the current board
+func (s *Source) updateList(listID string, update func(l *List)) error {
+	return s.updateFile("", func(b *Board) error {
+		l, _ := b.list(listID)
+		if l == nil {
+			return ErrListNotFound
+		}
+		update(l)
+		return nil
+	})
+}
+
+// updateCard applies the update to the card with the corresponding id of the current board
+func (s *Source) updateCard(cardID string, update func(c *Card, l *List, b *Board) error) error {
+	return s.updateFile("", func(b *Board) error {
+		c, l := b.card(cardID)
+		if c == nil {
+			return ErrCardNotFound
+		}
+		return update(c, l, b)
+	})
+}
+
+// updateFile reads the board file at the provided path, or the current one if empty, applies the
+// update and writes it back, so that changes made to the file in the meantime are kept
+func (s *Source) updateFile(path string, update func(b *Board) error) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if path == "" {
+		path = s.current
+	}
+	if path == "" {
+		return ErrBoardNotFound
+	}
+	b, err := readBoard(path)
+	if err != nil {
+		return err
+	}
+	if err := update(b); err != nil {
+		return err
+	}
+	// the lists and cards are written in order of position, with the ids assigned when loading
+	b.sort()
+	b.index()
+	return writeBoard(path, b)
+}
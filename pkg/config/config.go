@@ -0,0 +1,49 @@
+// Package config handles the optional configuration file of the application
+package config
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// File describes the content of the configuration file
+type File struct {
+	Keymap string              `json:"keymap"` // name of the key bindings preset
+	Keys   map[string][]string `json:"keys"`   // key bindings overriding the preset, by action name
+	Theme  string              `json:"theme"`  // name of the colour theme
+
+	NotifyCommand string `json:"notify_command"` // shell command receiving each notification as JSON on stdin
+	CopyFormat    string `json:"copy_format"`    // template of the text copied for a card, e.g. `#{{.IdShort}} {{.Name}}`
+}
+
+// DefaultPath returns the default location of the configuration file
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "trello-tui", "config.json")
+}
+
+// Load reads the configuration file at the provided path, a missing file results in an empty configuration
+func Load(path string) (File, error) {
+	var f File
+	if path == "" {
+		return f, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return f, nil
+	}
+	if err != nil {
+		return f, errors.Wrapf(err, "could not read configuration file %s", path)
+	}
+	if err := json.Unmarshal(data, &f); err != nil {
+		return f, errors.Wrapf(err, "could not parse configuration file %s", path)
+	}
+	return f, nil
+}
@@ -2,73 +2,280 @@ package domain
 
 import (
 	"sort"
+	"strings"
 	"time"
 )
 
+// PosStep is the position distance used by trello between consecutive lists or cards
+const PosStep = 65536
+
 // Board describes a trello board
 type Board struct {
+	ID          string
 	Updated     time.Time
 	IsEmpty     bool
 	Name        string
 	Description string
 	Lists       []List
+
+	cardLists map[string]int // index of the list containing each card, by card id
 }
 
 // CardByID returns a card with the corresponding id if available
-func (b *Board) CardByID(id int) (Card, bool) {
-	for _, l := range b.Lists {
-		if c, found := l.CardsByID[id]; found {
-			return c, true
-		}
+func (b *Board) CardByID(id string) (Card, bool) {
+	idx, found := b.CardList(id)
+	if !found {
+		return Card{}, false
+	}
+	return b.Lists[idx].CardsByID[id], true
+}
+
+// CardList returns the index of the list containing the card with the corresponding id
+func (b *Board) CardList(id string) (int, bool) {
+	idx, found := b.cardLists[id]
+	return idx, found
+}
+
+// WithCard returns a copy of the board where the card with the corresponding id has been
+// modified by the update function, the original board is left untouched
+func (b *Board) WithCard(id string, update func(c *Card)) (*Board, bool) {
+	i, found := b.CardList(id)
+	if !found {
+		return b, false
+	}
+	l := b.Lists[i]
+	c := l.CardsByID[id]
+	update(&c)
+
+	newBoard := *b
+	newBoard.Lists = make([]List, len(b.Lists))
+	copy(newBoard.Lists, b.Lists)
+	newBoard.Lists[i] = l.withCard(c)
+	return &newBoard, true
+}
+
+// ListPos returns the position for a list inserted at the provided index
+func (b *Board) ListPos(idx int) float64 {
+	switch {
+	case len(b.Lists) == 0:
+		return PosStep
+	case idx <= 0:
+		return b.Lists[0].Pos / 2
+	case idx >= len(b.Lists):
+		return b.Lists[len(b.Lists)-1].Pos + PosStep
+	}
+	return (b.Lists[idx-1].Pos + b.Lists[idx].Pos) / 2
+}
+
+// WithList returns a copy of the board with the list inserted at the provided index
+func (b *Board) WithList(idx int, l List) *Board {
+	if idx < 0 {
+		idx = 0
 	}
+	if idx > len(b.Lists) {
+		idx = len(b.Lists)
+	}
+	newBoard := *b
+	newBoard.Lists = make([]List, 0, len(b.Lists)+1)
+	newBoard.Lists = append(newBoard.Lists, b.Lists[:idx]...)
+	newBoard.Lists = append(newBoard.Lists, l)
+	newBoard.Lists = append(newBoard.Lists, b.Lists[idx:]...)
+	newBoard.index()
+	return &newBoard
+}
+
+// WithoutList returns a copy of the board without the list at the provided index
+func (b *Board) WithoutList(idx int) *Board {
+	newBoard := *b
+	newBoard.Lists = make([]List, 0, len(b.Lists))
+	newBoard.Lists = append(newBoard.Lists, b.Lists[:idx]...)
+	newBoard.Lists = append(newBoard.Lists, b.Lists[idx+1:]...)
+	newBoard.index()
+	return &newBoard
+}
+
+// WithListRenamed returns a copy of the board with the list at the provided index renamed
+func (b *Board) WithListRenamed(idx int, name string) *Board {
+	newBoard := *b
+	newBoard.Lists = make([]List, len(b.Lists))
+	copy(newBoard.Lists, b.Lists)
+	newBoard.Lists[idx].Name = name
+	return &newBoard
+}
+
+// WithListMoved returns a copy of the board with the list at index from moved to index to,
+// the moved list position is updated accordingly
+func (b *Board) WithListMoved(from, to int) *Board {
+	l := b.Lists[from]
+	without := b.WithoutList(from)
+	l.Pos = without.ListPos(to)
+	return without.WithList(to, l)
+}
+
+// WithCardMoved returns a copy of the board with the card with the corresponding id moved to the
+// list at index listIdx, inserted at index cardIdx, the moved card position is updated accordingly
+func (b *Board) WithCardMoved(id string, listIdx, cardIdx int) (*Board, bool) {
+	i, found := b.CardList(id)
+	if !found || listIdx < 0 || listIdx >= len(b.Lists) {
+		return b, false
+	}
+	c := b.Lists[i].CardsByID[id]
+
+	newBoard := *b
+	newBoard.Lists = make([]List, len(b.Lists))
+	copy(newBoard.Lists, b.Lists)
+	newBoard.Lists[i] = b.Lists[i].withoutCard(id)
+
+	to := newBoard.Lists[listIdx]
+	c.Pos = to.CardPos(cardIdx)
+	newBoard.Lists[listIdx] = to.withCard(c)
+	newBoard.index()
+	return &newBoard, true
+}
+
+// WithCardAdded returns a copy of the board with the card added to the list at index listIdx
+func (b *Board) WithCardAdded(listIdx int, c Card) (*Board, bool) {
+	if listIdx < 0 || listIdx >= len(b.Lists) {
+		return b, false
+	}
+	newBoard := *b
+	newBoard.Lists = make([]List, len(b.Lists))
+	copy(newBoard.Lists, b.Lists)
+	newBoard.Lists[listIdx] = b.Lists[listIdx].withCard(c)
+	newBoard.IsEmpty = false
+	newBoard.index()
+	return &newBoard, true
+}
 
-	return Card{}, false
+// index builds the index of the list containing each card
+func (b *Board) index() {
+	b.cardLists = make(map[string]int)
+	for i, l := range b.Lists {
+		for id := range l.CardsByID {
+			b.cardLists[id] = i
+		}
+	}
 }
 
 // NewBoard returns a new instance of Board
-func NewBoard(name, description string, lists []List, isEmpty bool) *Board {
-	return &Board{
+func NewBoard(id, name, description string, lists []List, isEmpty bool) *Board {
+	b := Board{
+		ID:          id,
 		Updated:     time.Now(),
 		IsEmpty:     isEmpty,
 		Name:        name,
 		Description: description,
 		Lists:       lists,
 	}
+	b.index()
+	return &b
 }
 
 // List describes a trello list
 type List struct {
 	ID        string
 	Name      string
-	CardsByID map[int]Card
-	CartIds   []int
+	Pos       float64
+	CardsByID map[string]Card
+	CartIds   []string
 }
 
-// NewList returns a new instance of List
-func NewList(id, name string, cardsByID map[int]Card) List {
-	cardIds := make([]int, 0, len(cardsByID))
+// NewList returns a new instance of List, cards are sorted by position
+func NewList(id, name string, pos float64, cardsByID map[string]Card) List {
+	cardIds := make([]string, 0, len(cardsByID))
 	for id := range cardsByID {
 		cardIds = append(cardIds, id)
 	}
 	sort.Slice(cardIds, func(i, j int) bool {
-		return cardsByID[cardIds[i]].Pos < cardsByID[cardIds[j]].Pos
+		a, b := cardsByID[cardIds[i]], cardsByID[cardIds[j]]
+		if a.Pos == b.Pos {
+			return a.ID < b.ID
+		}
+		return a.Pos < b.Pos
 	})
 
 	return List{
 		ID:        id,
 		Name:      name,
+		Pos:       pos,
 		CardsByID: cardsByID,
 		CartIds:   cardIds,
 	}
 }
 
+// CardPos returns the position for a card inserted at the provided index
+func (l *List) CardPos(idx int) float64 {
+	switch {
+	case len(l.CartIds) == 0:
+		return PosStep
+	case idx <= 0:
+		return l.CardsByID[l.CartIds[0]].Pos / 2
+	case idx >= len(l.CartIds):
+		return l.CardsByID[l.CartIds[len(l.CartIds)-1]].Pos + PosStep
+	}
+	return (l.CardsByID[l.CartIds[idx-1]].Pos + l.CardsByID[l.CartIds[idx]].Pos) / 2
+}
+
+// withCard returns a copy of the list including the card, replacing the card with the same id if any
+func (l *List) withCard(c Card) List {
+	cardsByID := make(map[string]Card, len(l.CardsByID)+1)
+	for cardID, card := range l.CardsByID {
+		cardsByID[cardID] = card
+	}
+	cardsByID[c.ID] = c
+	return NewList(l.ID, l.Name, l.Pos, cardsByID)
+}
+
+// withoutCard returns a copy of the list without the card with the corresponding id
+func (l *List) withoutCard(id string) List {
+	cardsByID := make(map[string]Card, len(l.CardsByID))
+	for cardID, card := range l.CardsByID {
+		if cardID != id {
+			cardsByID[cardID] = card
+		}
+	}
+	return NewList(l.ID, l.Name, l.Pos, cardsByID)
+}
+
 // Card describes a trello card which can be part of a trello list
 type Card struct {
 	ID          string
+	IdShort     int // number of the card within the board, for display
 	Name        string
 	Description string
 	Pos         float64
 	Labels      []CardLabel
+	Due         *Due
+	Assigned    bool // the user is a member of the card
+	Watched     bool // the user is subscribed to the card
+	Comments    int
+	Members     []string // full names of the members of the card
+	Checklists  []Checklist
+	URL         string // short URL of the card, empty if the card is not on trello
+	Archived    bool   // the card is archived, archived cards are only loaded on request
+}
+
+// Checklist describes a checklist of a card
+type Checklist struct {
+	Name  string
+	Items []ChecklistItem
+}
+
+// ChecklistItem describes an item of a checklist
+type ChecklistItem struct {
+	Name     string
+	Complete bool
+}
+
+// Completed returns the number of complete items of the checklist
+func (c Checklist) Completed() int {
+	var n int
+	for _, item := range c.Items {
+		if item.Complete {
+			n++
+		}
+	}
+	return n
 }
 
 // CardLabel describes a trello label which can be associated with a trello card
@@ -77,13 +284,46 @@ type CardLabel struct {
 	Color string
 }
 
+// LabelNames returns the names of the labels, or their colour if unnamed
+func LabelNames(labels []CardLabel) []string {
+	names := make([]string, len(labels))
+	for i, l := range labels {
+		names[i] = l.Name
+		if names[i] == "" {
+			names[i] = l.Color
+		}
+	}
+	return names
+}
+
+// MatchLabels returns the labels matching the names, by name or by colour if unnamed, ignoring
+// case, and the names not matching any label
+func MatchLabels(names []string, labels []CardLabel) (matched []CardLabel, unknown []string) {
+	for _, name := range names {
+		found := false
+		for _, l := range labels {
+			if strings.EqualFold(l.Name, name) || (l.Name == "" && strings.EqualFold(l.Color, name)) {
+				matched = append(matched, l)
+				found = true
+				break
+			}
+		}
+		if !found {
+			unknown = append(unknown, name)
+		}
+	}
+	return matched, unknown
+}
+
 // NewCard returns a news instance of the Card type
-func NewCard(id, name, description string, pos float64, labels []CardLabel) Card {
+func NewCard(id string, idShort int, name, description string, pos float64, labels []CardLabel, due *Due) Card {
 	return Card{
 		ID:          id,
+		IdShort:     idShort,
 		Name:        name,
 		Description: description,
 		Pos:         pos,
 		Labels:      labels,
+		Due:         due,
 	}
 }
@@ -0,0 +1,146 @@
+package domain
+
+// ChangeKind describes how a card or list changed between two versions of a board
+type ChangeKind int
+
+// Kinds of changes
+const (
+	Added ChangeKind = iota
+	Removed
+	Moved   // the list position changed, or the card was moved to another list or position
+	Changed // the content changed, e.g. the name or the due date
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Moved:
+		return "moved"
+	}
+	return "changed"
+}
+
+// Change describes a change of a card or list between two versions of a board
+type Change struct {
+	Kind       ChangeKind
+	ListID     string // list changed, or list containing the card changed
+	CardID     string // empty for list changes
+	FromListID string // list containing the card before it was moved to another list, if any
+}
+
+// IsCard returns true if the change concerns a card
+func (c Change) IsCard() bool {
+	return c.CardID != ""
+}
+
+// Diff returns the changes of the lists and cards of the board compared to a previous version
+// of the same board, a nil previous version results in every list and card being added
+func Diff(previous, b *Board) []Change {
+	if previous == nil {
+		previous = &Board{}
+	}
+	var changes []Change
+
+	previousLists := make(map[string]List, len(previous.Lists))
+	for _, l := range previous.Lists {
+		previousLists[l.ID] = l
+	}
+	lists := make(map[string]bool, len(b.Lists))
+	for _, l := range b.Lists {
+		lists[l.ID] = true
+		prev, found := previousLists[l.ID]
+		switch {
+		case !found:
+			changes = append(changes, Change{Kind: Added, ListID: l.ID})
+		case prev.Pos != l.Pos:
+			changes = append(changes, Change{Kind: Moved, ListID: l.ID})
+		case prev.Name != l.Name:
+			changes = append(changes, Change{Kind: Changed, ListID: l.ID})
+		}
+	}
+	for _, l := range previous.Lists {
+		if !lists[l.ID] {
+			changes = append(changes, Change{Kind: Removed, ListID: l.ID})
+		}
+	}
+
+	for _, l := range b.Lists {
+		for _, id := range l.CartIds {
+			c := l.CardsByID[id]
+			prevListIdx, found := previous.CardList(id)
+			if !found {
+				changes = append(changes, Change{Kind: Added, ListID: l.ID, CardID: id})
+				continue
+			}
+			prevList := previous.Lists[prevListIdx]
+			prev := prevList.CardsByID[id]
+			switch {
+			case prevList.ID != l.ID:
+				changes = append(changes, Change{Kind: Moved, ListID: l.ID, CardID: id, FromListID: prevList.ID})
+			case prev.Pos != c.Pos:
+				changes = append(changes, Change{Kind: Moved, ListID: l.ID, CardID: id})
+			case !prev.Equal(c):
+				changes = append(changes, Change{Kind: Changed, ListID: l.ID, CardID: id})
+			}
+		}
+	}
+	for _, l := range previous.Lists {
+		for _, id := range l.CartIds {
+			if _, found := b.CardList(id); !found {
+				changes = append(changes, Change{Kind: Removed, ListID: l.ID, CardID: id})
+			}
+		}
+	}
+	return changes
+}
+
+// Equal returns true if the cards have the same content, regardless of their position
+func (c Card) Equal(other Card) bool {
+	if c.ID != other.ID || c.IdShort != other.IdShort || c.Name != other.Name || c.Description != other.Description || c.URL != other.URL {
+		return false
+	}
+	if c.Assigned != other.Assigned || c.Watched != other.Watched || c.Comments != other.Comments || c.Archived != other.Archived {
+		return false
+	}
+	if len(c.Labels) != len(other.Labels) || len(c.Members) != len(other.Members) || len(c.Checklists) != len(other.Checklists) {
+		return false
+	}
+	for i := range c.Labels {
+		if c.Labels[i] != other.Labels[i] {
+			return false
+		}
+	}
+	for i := range c.Members {
+		if c.Members[i] != other.Members[i] {
+			return false
+		}
+	}
+	for i := range c.Checklists {
+		if !c.Checklists[i].equal(other.Checklists[i]) {
+			return false
+		}
+	}
+	switch {
+	case c.Due == nil || other.Due == nil:
+		return c.Due == other.Due
+	case !c.Due.Time.Equal(other.Due.Time):
+		return false
+	}
+	return c.Due.Complete == other.Due.Complete
+}
+
+// equal returns true if the checklists have the same name and items
+func (c Checklist) equal(other Checklist) bool {
+	if c.Name != other.Name || len(c.Items) != len(other.Items) {
+		return false
+	}
+	for i := range c.Items {
+		if c.Items[i] != other.Items[i] {
+			return false
+		}
+	}
+	return true
+}
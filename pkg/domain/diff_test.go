@@ -0,0 +1,145 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testBoard returns a board with two lists, the first with two cards and the second with one
+func testBoard() *Board {
+	due := Due{Time: time.Date(2030, time.January, 1, 12, 0, 0, 0, time.UTC)}
+	return NewBoard("b", "Board", "", []List{
+		{ID: "l1", Name: "To do", Pos: 1, CartIds: []string{"c1", "c2"}, CardsByID: map[string]Card{
+			"c1": {ID: "c1", IdShort: 1, Name: "First", Pos: 1, Labels: []CardLabel{{Name: "bug", Color: "red"}}},
+			"c2": {ID: "c2", IdShort: 2, Name: "Second", Pos: 2, Due: &due},
+		}},
+		{ID: "l2", Name: "Done", Pos: 2, CartIds: []string{"c3"}, CardsByID: map[string]Card{
+			"c3": {ID: "c3", IdShort: 3, Name: "Third", Pos: 1},
+		}},
+	}, false)
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous *Board
+		update   func(b *Board) *Board // returns the new version of the board, the test board if nil
+		want     []Change
+	}{
+		{
+			name:     "unchanged",
+			previous: testBoard(),
+			update:   func(b *Board) *Board { return b },
+		},
+		{
+			name: "no previous version",
+			want: []Change{
+				{Kind: Added, ListID: "l1"},
+				{Kind: Added, ListID: "l2"},
+				{Kind: Added, ListID: "l1", CardID: "c1"},
+				{Kind: Added, ListID: "l1", CardID: "c2"},
+				{Kind: Added, ListID: "l2", CardID: "c3"},
+			},
+		},
+		{
+			name:     "card added",
+			previous: testBoard(),
+			update: func(b *Board) *Board {
+				newBoard, _ := b.WithCardAdded(1, Card{ID: "c4", Name: "Fourth", Pos: 2})
+				return newBoard
+			},
+			want: []Change{{Kind: Added, ListID: "l2", CardID: "c4"}},
+		},
+		{
+			name:     "card removed",
+			previous: testBoard(),
+			update: func(b *Board) *Board {
+				lists := []List{{ID: "l1", Name: "To do", Pos: 1, CartIds: []string{"c2"}, CardsByID: map[string]Card{
+					"c2": b.Lists[0].CardsByID["c2"],
+				}}, b.Lists[1]}
+				return NewBoard(b.ID, b.Name, b.Description, lists, false)
+			},
+			want: []Change{{Kind: Removed, ListID: "l1", CardID: "c1"}},
+		},
+		{
+			name:     "card moved to another list",
+			previous: testBoard(),
+			update: func(b *Board) *Board {
+				newBoard, _ := b.WithCardMoved("c1", 1, 0)
+				return newBoard
+			},
+			want: []Change{{Kind: Moved, ListID: "l2", CardID: "c1", FromListID: "l1"}},
+		},
+		{
+			name:     "card moved within the list",
+			previous: testBoard(),
+			update: func(b *Board) *Board {
+				newBoard, _ := b.WithCardMoved("c2", 0, 0)
+				return newBoard
+			},
+			want: []Change{{Kind: Moved, ListID: "l1", CardID: "c2"}},
+		},
+		{
+			name:     "card renamed",
+			previous: testBoard(),
+			update: func(b *Board) *Board {
+				newBoard, _ := b.WithCard("c3", func(c *Card) { c.Name = "Renamed" })
+				return newBoard
+			},
+			want: []Change{{Kind: Changed, ListID: "l2", CardID: "c3"}},
+		},
+		{
+			name:     "due date completed",
+			previous: testBoard(),
+			update: func(b *Board) *Board {
+				newBoard, _ := b.WithCard("c2", func(c *Card) {
+					due := *c.Due
+					due.Complete = true
+					c.Due = &due
+				})
+				return newBoard
+			},
+			want: []Change{{Kind: Changed, ListID: "l1", CardID: "c2"}},
+		},
+		{
+			name:     "list renamed",
+			previous: testBoard(),
+			update: func(b *Board) *Board {
+				return b.WithListRenamed(1, "Shipped")
+			},
+			want: []Change{{Kind: Changed, ListID: "l2"}},
+		},
+		{
+			name:     "list moved",
+			previous: testBoard(),
+			update: func(b *Board) *Board {
+				return b.WithListMoved(1, 0)
+			},
+			want: []Change{{Kind: Moved, ListID: "l2"}},
+		},
+		{
+			name:     "list removed with its cards",
+			previous: testBoard(),
+			update: func(b *Board) *Board {
+				return b.WithoutList(1)
+			},
+			want: []Change{
+				{Kind: Removed, ListID: "l2"},
+				{Kind: Removed, ListID: "l2", CardID: "c3"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := testBoard()
+			if tt.update != nil {
+				b = tt.update(b)
+			}
+			got := Diff(tt.previous, b)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DueSoonThreshold is the time before the due date after which a card is considered due soon
+const DueSoonThreshold = time.Hour * 24
+
+// defaultDueHour is the hour of the day used when the due date input does not specify a time
+const defaultDueHour = 12
+
+// ErrInvalidDue is returned when a due date input could not be parsed
+var ErrInvalidDue = errors.New("invalid due date")
+
+// Due describes the due date of a trello card
+type Due struct {
+	Time     time.Time
+	Complete bool
+}
+
+// DueStatus describes the urgency of a due date
+type DueStatus int
+
+const (
+	// DueLater is the status of a due date further away than DueSoonThreshold
+	DueLater DueStatus = iota
+	// DueSoon is the status of a due date closer than DueSoonThreshold
+	DueSoon
+	// DueOverdue is the status of a due date in the past
+	DueOverdue
+	// DueComplete is the status of a due date marked as complete
+	DueComplete
+)
+
+// Status returns the urgency of the due date relative to now
+func (d Due) Status(now time.Time) DueStatus {
+	switch {
+	case d.Complete:
+		return DueComplete
+	case d.Time.Before(now):
+		return DueOverdue
+	case d.Time.Sub(now) < DueSoonThreshold:
+		return DueSoon
+	}
+	return DueLater
+}
+
+// ParseDue parses a due date input relative to now, in now's location.
+// Supported inputs are:
+// - relative offsets: `+30m`, `+2h`, `+3d`, `+1w`
+// - days: `today`, `tomorrow`, weekday names (`fri`, `friday`) optionally followed by a time (`tomorrow 17:00`)
+// - ISO dates: `2006-01-02`, `2006-01-02 15:04`, `2006-01-02T15:04`, RFC3339
+// When no time is specified the due date is set at noon.
+func ParseDue(input string, now time.Time) (time.Time, error) {
+	input = strings.ToLower(strings.TrimSpace(input))
+	if input == "" {
+		return time.Time{}, ErrInvalidDue
+	}
+
+	if strings.HasPrefix(input, "+") {
+		return parseDueOffset(input[1:], now)
+	}
+
+	if t, err := time.Parse(time.RFC3339, strings.ToUpper(input)); err == nil {
+		return t.In(now.Location()), nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02t15:04"} {
+		if t, err := time.ParseInLocation(layout, input, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+
+	fields := strings.Fields(input)
+	if len(fields) > 2 {
+		return time.Time{}, ErrInvalidDue
+	}
+	hour, minute := defaultDueHour, 0
+	if len(fields) == 2 {
+		var err error
+		if hour, minute, err = parseDueClock(fields[1]); err != nil {
+			return time.Time{}, err
+		}
+	}
+
+	day, err := parseDueDay(fields[0], now)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, now.Location()), nil
+}
+
+// parseDueOffset parses an offset such as `3d` and adds it to now
+func parseDueOffset(offset string, now time.Time) (time.Time, error) {
+	if len(offset) < 2 {
+		return time.Time{}, ErrInvalidDue
+	}
+	n, err := strconv.Atoi(offset[:len(offset)-1])
+	if err != nil || n < 0 {
+		return time.Time{}, ErrInvalidDue
+	}
+	switch offset[len(offset)-1] {
+	case 'm':
+		return now.Add(time.Duration(n) * time.Minute), nil
+	case 'h':
+		return now.Add(time.Duration(n) * time.Hour), nil
+	case 'd':
+		return now.AddDate(0, 0, n), nil
+	case 'w':
+		return now.AddDate(0, 0, 7*n), nil
+	}
+	return time.Time{}, ErrInvalidDue
+}
+
+// parseDueDay parses a day name or date and returns a time on that day
+func parseDueDay(day string, now time.Time) (time.Time, error) {
+	switch day {
+	case "today":
+		return now, nil
+	case "tomorrow", "tmr":
+		return now.AddDate(0, 0, 1), nil
+	}
+
+	if t, err := time.ParseInLocation("2006-01-02", day, now.Location()); err == nil {
+		return t, nil
+	}
+
+	for wd := time.Sunday; wd <= time.Saturday; wd++ {
+		name := strings.ToLower(wd.String())
+		if day == name || day == name[:3] {
+			// weekday names always refer to the next occurrence, excluding today
+			delta := (int(wd) - int(now.Weekday()) + 7) % 7
+			if delta == 0 {
+				delta = 7
+			}
+			return now.AddDate(0, 0, delta), nil
+		}
+	}
+	return time.Time{}, ErrInvalidDue
+}
+
+// parseDueClock parses a time of the day in the `15:04` or `15` formats
+func parseDueClock(clock string) (int, int, error) {
+	for _, layout := range []string{"15:04", "15"} {
+		if t, err := time.Parse(layout, clock); err == nil {
+			return t.Hour(), t.Minute(), nil
+		}
+	}
+	return 0, 0, ErrInvalidDue
+}
@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDue(t *testing.T) {
+	loc := time.FixedZone("CET", 3600)
+	// a Wednesday
+	now := time.Date(2030, time.January, 16, 9, 30, 0, 0, loc)
+	tests := []struct {
+		input string
+		want  time.Time
+	}{
+		{input: "+30m", want: time.Date(2030, time.January, 16, 10, 0, 0, 0, loc)},
+		{input: "+2h", want: time.Date(2030, time.January, 16, 11, 30, 0, 0, loc)},
+		{input: "+3d", want: time.Date(2030, time.January, 19, 9, 30, 0, 0, loc)},
+		{input: "+1w", want: time.Date(2030, time.January, 23, 9, 30, 0, 0, loc)},
+		{input: "+0d", want: now},
+		{input: "today", want: time.Date(2030, time.January, 16, 12, 0, 0, 0, loc)},
+		{input: "  Today 17:45 ", want: time.Date(2030, time.January, 16, 17, 45, 0, 0, loc)},
+		{input: "tomorrow", want: time.Date(2030, time.January, 17, 12, 0, 0, 0, loc)},
+		{input: "tmr 8", want: time.Date(2030, time.January, 17, 8, 0, 0, 0, loc)},
+		{input: "fri", want: time.Date(2030, time.January, 18, 12, 0, 0, 0, loc)},
+		{input: "Friday 17:00", want: time.Date(2030, time.January, 18, 17, 0, 0, 0, loc)},
+		// weekdays before the current one wrap around to the next week
+		{input: "mon", want: time.Date(2030, time.January, 21, 12, 0, 0, 0, loc)},
+		// the current weekday refers to the next week, not today
+		{input: "wednesday", want: time.Date(2030, time.January, 23, 12, 0, 0, 0, loc)},
+		{input: "2030-03-01", want: time.Date(2030, time.March, 1, 12, 0, 0, 0, loc)},
+		{input: "2030-03-01 9:05", want: time.Date(2030, time.March, 1, 9, 5, 0, 0, loc)},
+		{input: "2030-03-01 09:05", want: time.Date(2030, time.March, 1, 9, 5, 0, 0, loc)},
+		{input: "2030-03-01T09:05", want: time.Date(2030, time.March, 1, 9, 5, 0, 0, loc)},
+		{input: "2030-03-01T09:05:00Z", want: time.Date(2030, time.March, 1, 10, 5, 0, 0, loc)},
+		{input: "2030-03-01t09:05:00+02:00", want: time.Date(2030, time.March, 1, 8, 5, 0, 0, loc)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseDue(tt.input, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(tt.want) || got.Location() != loc {
+				t.Errorf("ParseDue(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDueInvalid(t *testing.T) {
+	now := time.Date(2030, time.January, 16, 9, 30, 0, 0, time.UTC)
+	for _, input := range []string{"", "  ", "+", "+d", "+-1d", "+3y", "+1.5h", "someday", "fr", "tomorrow 25:00", "tomorrow at 5", "2030-13-01", "2030-02-30"} {
+		if got, err := ParseDue(input, now); err != ErrInvalidDue {
+			t.Errorf("ParseDue(%q) = %v, %v, want ErrInvalidDue", input, got, err)
+		}
+	}
+}
+
+func TestDueStatus(t *testing.T) {
+	now := time.Date(2030, time.January, 16, 9, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		due  Due
+		want DueStatus
+	}{
+		{name: "later", due: Due{Time: now.Add(DueSoonThreshold)}, want: DueLater},
+		{name: "soon", due: Due{Time: now.Add(DueSoonThreshold - time.Second)}, want: DueSoon},
+		{name: "now is not overdue", due: Due{Time: now}, want: DueSoon},
+		{name: "overdue", due: Due{Time: now.Add(-time.Second)}, want: DueOverdue},
+		{name: "complete overdue", due: Due{Time: now.Add(-time.Hour), Complete: true}, want: DueComplete},
+		{name: "complete later", due: Due{Time: now.Add(2 * DueSoonThreshold), Complete: true}, want: DueComplete},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.due.Status(now); got != tt.want {
+				t.Errorf("Status() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// Notification describes a trello notification of the user, e.g. a mention in a comment
+type Notification struct {
+	ID         string
+	Type       string // trello notification type, e.g. `mentionedOnCard`
+	Date       time.Time
+	Unread     bool
+	Creator    string // full name of the member who caused the notification
+	BoardName  string
+	CardID     string // empty if the notification is not about a card
+	CardNumber int
+	CardName   string
+	Text       string // text of the comment, if any
+}
+
+// notificationDescriptions describes the most common trello notification types
+var notificationDescriptions = map[string]string{
+	"mentionedOnCard":            "mentioned you on",
+	"commentCard":                "commented on",
+	"addedToCard":                "added you to",
+	"removedFromCard":            "removed you from",
+	"changeCard":                 "changed",
+	"createdCard":                "created",
+	"addedAttachmentToCard":      "attached a file to",
+	"addedToBoard":               "added you to the board",
+	"removedFromBoard":           "removed you from the board",
+	"addAdminToBoard":            "made you admin of the board",
+	"makeAdminOfBoard":           "made you admin of the board",
+	"closeBoard":                 "closed the board",
+	"reactionAdded":              "reacted on",
+	"addedMemberToCard":          "added a member to",
+	"removedMemberFromCard":      "removed a member from",
+	"updateCheckItemStateOnCard": "completed an item on",
+}
+
+// Message returns a short description of the notification, e.g. `Jane mentioned you on Fix login`
+func (n Notification) Message() string {
+	subject := n.CardName
+	if subject == "" {
+		subject = n.BoardName
+	}
+	if n.Type == "cardDueSoon" {
+		return subject + " is due soon"
+	}
+	description, found := notificationDescriptions[n.Type]
+	if !found {
+		description = n.Type
+	}
+	return strings.TrimSpace(n.Creator + " " + description + " " + subject)
+}
@@ -0,0 +1,294 @@
+// Package export serializes boards to Markdown, JSON and CSV, e.g. for pasting board snapshots
+// into reports
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/pkg/errors"
+
+	"github.com/giannimassi/trello-tui/pkg/domain"
+)
+
+// Supported formats
+const (
+	Markdown = "md"
+	JSON     = "json"
+	CSV      = "csv"
+)
+
+// dateLayout is the layout of due dates in Markdown and CSV exports
+const dateLayout = "2006-01-02 15:04"
+
+// CSVHeader is the header of CSV exports, also accepted by imports
+var CSVHeader = []string{"list", "number", "title", "description", "labels", "members", "due", "due_complete", "checklists"}
+
+// writers serialize boards, by format
+var writers = map[string]func(w io.Writer, b *domain.Board) error{
+	Markdown: writeMarkdown,
+	JSON:     writeJSON,
+	CSV:      writeCSV,
+}
+
+// Formats returns the names of the supported formats
+func Formats() []string {
+	formats := make([]string, 0, len(writers))
+	for f := range writers {
+		formats = append(formats, f)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// FormatOf returns the format corresponding to the extension of the path, or an empty string if
+// the extension does not match a supported format
+func FormatOf(path string) string {
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
+	if ext == "markdown" {
+		ext = Markdown
+	}
+	if _, found := writers[ext]; !found {
+		return ""
+	}
+	return ext
+}
+
+// Write serializes the board to w in the provided format
+func Write(w io.Writer, b *domain.Board, format string) error {
+	write, found := writers[format]
+	if !found {
+		return errors.Errorf("unknown export format %q (available: %s)", format, strings.Join(Formats(), ", "))
+	}
+	return write(w, b)
+}
+
+// cards returns the cards of the list sorted by position
+func cards(l domain.List) []domain.Card {
+	cards := make([]domain.Card, len(l.CartIds))
+	for i, id := range l.CartIds {
+		cards[i] = l.CardsByID[id]
+	}
+	return cards
+}
+
+// writeMarkdown writes the board as a Markdown document with a section per list and a checklist
+// item per card, followed by its details
+func writeMarkdown(w io.Writer, b *domain.Board) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n\n", b.Name)
+	if b.Description != "" {
+		fmt.Fprintf(&sb, "%s\n\n", b.Description)
+	}
+	for _, l := range b.Lists {
+		fmt.Fprintf(&sb, "## %s\n\n", l.Name)
+		for _, c := range cards(l) {
+			box := " "
+			if c.Due != nil && c.Due.Complete {
+				box = "x"
+			}
+			fmt.Fprintf(&sb, "- [%s] %s", box, c.Name)
+			if c.IdShort != 0 {
+				fmt.Fprintf(&sb, " (#%d)", c.IdShort)
+			}
+			for _, label := range domain.LabelNames(c.Labels) {
+				fmt.Fprintf(&sb, " `%s`", label)
+			}
+			sb.WriteString("\n")
+
+			var details []string
+			if c.Due != nil {
+				details = append(details, "due "+c.Due.Time.Format(dateLayout))
+			}
+			if len(c.Members) > 0 {
+				details = append(details, strings.Join(c.Members, ", "))
+			}
+			if len(details) > 0 {
+				fmt.Fprintf(&sb, "  %s\n", strings.Join(details, " · "))
+			}
+			if c.Description != "" {
+				for _, line := range strings.Split(c.Description, "\n") {
+					fmt.Fprintf(&sb, "  > %s\n", line)
+				}
+			}
+			for _, cl := range c.Checklists {
+				fmt.Fprintf(&sb, "  - %s (%d/%d)\n", cl.Name, cl.Completed(), len(cl.Items))
+				for _, item := range cl.Items {
+					box := " "
+					if item.Complete {
+						box = "x"
+					}
+					fmt.Fprintf(&sb, "    - [%s] %s\n", box, item.Name)
+				}
+			}
+		}
+		if len(l.CartIds) > 0 {
+			sb.WriteString("\n")
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return errors.Wrap(err, "could not write export")
+}
+
+// jsonBoard and jsonList describe the JSON export
+type jsonBoard struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Lists       []jsonList `json:"lists"`
+}
+
+type jsonList struct {
+	Name  string `json:"name"`
+	Cards []Card `json:"cards"`
+}
+
+// Card is the JSON representation of a card, used by the JSON export and by the subcommands
+// printing cards as JSON
+type Card struct {
+	ID          string      `json:"id"`
+	Number      int         `json:"number,omitempty"`
+	List        string      `json:"list,omitempty"` // name of the list, omitted within lists
+	Title       string      `json:"title"`
+	Description string      `json:"description,omitempty"`
+	Labels      []string    `json:"labels,omitempty"`
+	Members     []string    `json:"members,omitempty"`
+	Due         *time.Time  `json:"due,omitempty"`
+	DueComplete bool        `json:"due_complete,omitempty"`
+	Checklists  []Checklist `json:"checklists,omitempty"`
+	URL         string      `json:"url,omitempty"`
+}
+
+// Checklist is the JSON representation of a checklist
+type Checklist struct {
+	Name  string          `json:"name"`
+	Items []ChecklistItem `json:"items"`
+}
+
+// ChecklistItem is the JSON representation of a checklist item
+type ChecklistItem struct {
+	Name     string `json:"name"`
+	Complete bool   `json:"complete"`
+}
+
+// NewCard returns the JSON representation of the card, list is the name of the list containing
+// it, empty if the card is listed within its list
+func NewCard(c domain.Card, list string) Card {
+	card := Card{
+		ID:          c.ID,
+		Number:      c.IdShort,
+		List:        list,
+		Title:       c.Name,
+		Description: c.Description,
+		Members:     c.Members,
+		URL:         c.URL,
+	}
+	if len(c.Labels) > 0 {
+		card.Labels = domain.LabelNames(c.Labels)
+	}
+	if c.Due != nil {
+		due := c.Due.Time
+		card.Due = &due
+		card.DueComplete = c.Due.Complete
+	}
+	for _, cl := range c.Checklists {
+		checklist := Checklist{Name: cl.Name, Items: make([]ChecklistItem, len(cl.Items))}
+		for j, item := range cl.Items {
+			checklist.Items[j] = ChecklistItem{Name: item.Name, Complete: item.Complete}
+		}
+		card.Checklists = append(card.Checklists, checklist)
+	}
+	return card
+}
+
+// writeJSON writes the board as an indented JSON document
+func writeJSON(w io.Writer, b *domain.Board) error {
+	board := jsonBoard{Name: b.Name, Description: b.Description, Lists: make([]jsonList, len(b.Lists))}
+	for i, l := range b.Lists {
+		board.Lists[i] = jsonList{Name: l.Name, Cards: []Card{}}
+		for _, c := range cards(l) {
+			board.Lists[i].Cards = append(board.Lists[i].Cards, NewCard(c, ""))
+		}
+	}
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	return errors.Wrap(e.Encode(board), "could not write export")
+}
+
+// writeCSV writes the board as CSV with a row per card, multiple labels and members are separated
+// by commas, checklists are summarized by their name and progress
+func writeCSV(w io.Writer, b *domain.Board) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(CSVHeader); err != nil {
+		return errors.Wrap(err, "could not write export")
+	}
+	for _, l := range b.Lists {
+		for _, c := range cards(l) {
+			var due, complete string
+			if c.Due != nil {
+				due = c.Due.Time.Format(dateLayout)
+				complete = strconv.FormatBool(c.Due.Complete)
+			}
+			checklists := make([]string, len(c.Checklists))
+			for i, cl := range c.Checklists {
+				checklists[i] = fmt.Sprintf("%s (%d/%d)", cl.Name, cl.Completed(), len(cl.Items))
+			}
+			record := []string{
+				l.Name,
+				strconv.Itoa(c.IdShort),
+				c.Name,
+				c.Description,
+				strings.Join(domain.LabelNames(c.Labels), ", "),
+				strings.Join(c.Members, ", "),
+				due,
+				complete,
+				strings.Join(checklists, ", "),
+			}
+			if err := cw.Write(record); err != nil {
+				return errors.Wrap(err, "could not write export")
+			}
+		}
+	}
+	cw.Flush()
+	return errors.Wrap(cw.Error(), "could not write export")
+}
+
+// DefaultPath returns the name of the Markdown file a board is exported to by default, made of
+// the board name and the date
+func DefaultPath(b *domain.Board, now time.Time) string {
+	name := strings.Join(strings.FieldsFunc(strings.ToLower(b.Name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), "-")
+	if name == "" {
+		name = "board"
+	}
+	return name + "-" + now.Format("2006-01-02") + "." + Markdown
+}
+
+// WriteFile serializes the board to the file at the provided path, in the format corresponding
+// to its extension if format is empty
+func WriteFile(path string, b *domain.Board, format string) error {
+	if format == "" {
+		format = FormatOf(path)
+	}
+	if format == "" {
+		return errors.Errorf("unknown export format for %s (available: %s)", path, strings.Join(Formats(), ", "))
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return errors.Wrapf(err, "could not create %s", path)
+	}
+	if err := Write(f, b, format); err != nil {
+		f.Close()
+		return err
+	}
+	return errors.Wrapf(f.Close(), "could not write %s", path)
+}
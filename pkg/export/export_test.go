@@ -0,0 +1,97 @@
+package export_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/giannimassi/trello-tui/pkg/domain"
+	"github.com/giannimassi/trello-tui/pkg/export"
+	"github.com/giannimassi/trello-tui/pkg/importer"
+)
+
+// testBoard returns a board with cards using the characters which need quoting in CSV files
+func testBoard() *domain.Board {
+	due := domain.Due{Time: time.Date(2030, time.January, 18, 17, 30, 0, 0, time.Local)}
+	done := domain.Due{Time: time.Date(2030, time.January, 10, 9, 0, 0, 0, time.Local), Complete: true}
+	login := domain.NewCard("c1", 1, `Fix "login", again`, "Happens when the session expires\nand the page is reloaded", 1,
+		[]domain.CardLabel{{Name: "bug", Color: "red"}, {Color: "orange"}}, &due)
+	login.Members = []string{"Jane Doe", "Demo User"}
+	release := domain.NewCard("c2", 2, "Release", "", 2, nil, &done)
+	release.Checklists = []domain.Checklist{{Name: "Steps", Items: []domain.ChecklistItem{{Name: "Tag", Complete: true}, {Name: "Publish"}}}}
+	docs := domain.NewCard("c3", 3, "Write the docs", "", 1, []domain.CardLabel{{Name: "docs"}}, nil)
+	return domain.NewBoard("b", "Release, 2030", "", []domain.List{
+		domain.NewList("l1", "To Do", 1, map[string]domain.Card{"c1": login, "c2": release}),
+		domain.NewList("l2", "Done", 2, map[string]domain.Card{"c3": docs}),
+		domain.NewList("l3", "Empty", 3, map[string]domain.Card{}),
+	}, false)
+}
+
+func TestCSVRoundTrip(t *testing.T) {
+	b := testBoard()
+	var buf bytes.Buffer
+	if err := export.Write(&buf, b, export.CSV); err != nil {
+		t.Fatal(err)
+	}
+	cards, err := importer.Read(buf.Bytes(), importer.CSV)
+	if err != nil {
+		t.Fatalf("could not import the export: %v\n%s", err, buf.String())
+	}
+
+	var want []domain.Card
+	for _, l := range b.Lists {
+		for _, id := range l.CartIds {
+			want = append(want, l.CardsByID[id])
+		}
+	}
+	if len(cards) != len(want) {
+		t.Fatalf("imported %d cards, want %d", len(cards), len(want))
+	}
+	for i, c := range cards {
+		w := want[i]
+		if c.Name != w.Name || c.Description != w.Description {
+			t.Errorf("card %d = %q %q, want %q %q", i, c.Name, c.Description, w.Name, w.Description)
+		}
+		if got, want := domain.LabelNames(c.Labels), domain.LabelNames(w.Labels); len(got)+len(want) > 0 && !reflect.DeepEqual(got, want) {
+			t.Errorf("card %d labels = %v, want %v", i, got, want)
+		}
+		if len(c.Members)+len(w.Members) > 0 && !reflect.DeepEqual(c.Members, w.Members) {
+			t.Errorf("card %d members = %v, want %v", i, c.Members, w.Members)
+		}
+		switch {
+		case (c.Due == nil) != (w.Due == nil):
+			t.Errorf("card %d due = %+v, want %+v", i, c.Due, w.Due)
+		case c.Due != nil && (!c.Due.Time.Equal(w.Due.Time) || c.Due.Complete != w.Due.Complete):
+			t.Errorf("card %d due = %+v, want %+v", i, *c.Due, *w.Due)
+		}
+	}
+}
+
+func TestFormatOf(t *testing.T) {
+	tests := map[string]string{
+		"board.md":       export.Markdown,
+		"board.markdown": export.Markdown,
+		"board.JSON":     export.JSON,
+		"board.csv":      export.CSV,
+		"board.txt":      "",
+	}
+	for path, want := range tests {
+		if got := export.FormatOf(path); got != want {
+			t.Errorf("FormatOf(%q) = %q, want %q", path, got, want)
+		}
+	}
+	if err := export.Write(&bytes.Buffer{}, testBoard(), "txt"); err == nil {
+		t.Error("no error for an unknown format")
+	}
+}
+
+func TestDefaultPath(t *testing.T) {
+	now := time.Date(2030, time.January, 18, 17, 30, 0, 0, time.UTC)
+	if got := export.DefaultPath(testBoard(), now); got != "release-2030-2030-01-18.md" {
+		t.Errorf("DefaultPath() = %q", got)
+	}
+	if got := export.DefaultPath(&domain.Board{Name: "!!"}, now); got != "board-2030-01-18.md" {
+		t.Errorf("DefaultPath() = %q for a board without letters", got)
+	}
+}
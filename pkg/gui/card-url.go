@@ -0,0 +1,97 @@
+package gui
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"text/template"
+
+	"github.com/giannimassi/trello-tui/pkg/store"
+	"github.com/pkg/errors"
+)
+
+// DefaultCopyFormat is the template of the text copied for a card by default, its URL
+const DefaultCopyFormat = "{{.URL}}"
+
+// copiedCard is the card the copy format template is executed with
+type copiedCard struct {
+	ID      string
+	IdShort int
+	Name    string
+	List    string
+	URL     string
+}
+
+// newCopyFormat parses the template of the text copied for a card, e.g. `#{{.IdShort}} {{.Name}}`
+func newCopyFormat(format string) (*template.Template, error) {
+	if format == "" {
+		format = DefaultCopyFormat
+	}
+	t, err := template.New("copy").Parse(format)
+	return t, errors.Wrapf(err, "invalid copy format %q", format)
+}
+
+// cardText returns the text copied for the card with the provided id
+func cardText(t *template.Template, s store.CardState, listName func(idx int) string, id string) (string, error) {
+	c := copiedCard{
+		ID:      id,
+		IdShort: s.CardIdShort(id),
+		Name:    s.CardName(id),
+		URL:     s.CardURL(id),
+	}
+	if idx, found := s.CardList(id); found {
+		c.List = listName(idx)
+	}
+	var sb strings.Builder
+	if err := t.Execute(&sb, c); err != nil {
+		return "", errors.Wrap(err, "invalid copy format")
+	}
+	return sb.String(), nil
+}
+
+// openURL opens the url with the first command of $BROWSER, where `%s` is replaced by the url or
+// the url is appended, or with the opener of the platform if $BROWSER is not set
+func openURL(url string) error {
+	var cmd *exec.Cmd
+	if browser := strings.Split(os.Getenv("BROWSER"), string(os.PathListSeparator))[0]; browser != "" {
+		args := strings.Fields(browser)
+		if strings.Contains(browser, "%s") {
+			for i := range args {
+				args[i] = strings.Replace(args[i], "%s", url, -1)
+			}
+		} else {
+			args = append(args, url)
+		}
+		cmd = exec.Command(args[0], args[1:]...)
+	} else {
+		switch runtime.GOOS {
+		case "darwin":
+			cmd = exec.Command("open", url)
+		case "windows":
+			cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+		default:
+			cmd = exec.Command("xdg-open", url)
+		}
+	}
+	if err := cmd.Start(); err != nil {
+		return errors.Wrapf(err, "could not run %s", cmd.Path)
+	}
+	// the browser is left running, the process is only waited for so that it does not linger
+	go func() { _ = cmd.Wait() }()
+	return nil
+}
+
+// copyToClipboard sets the clipboard of the terminal to the text with an OSC 52 escape sequence,
+// which is supported over SSH as well, the sequence is wrapped for passing through tmux
+func copyToClipboard(w io.Writer, text string) error {
+	sequence := fmt.Sprintf("\x1b]52;c;%s\a", base64.StdEncoding.EncodeToString([]byte(text)))
+	if os.Getenv("TMUX") != "" {
+		sequence = "\x1bPtmux;\x1b" + sequence + "\x1b\\"
+	}
+	_, err := io.WriteString(w, sequence)
+	return errors.Wrap(err, "could not write to the terminal")
+}
@@ -1,7 +1,11 @@
 package gui
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/gdamore/tcell"
+	"github.com/giannimassi/trello-tui/pkg/domain"
 	"github.com/giannimassi/trello-tui/pkg/store"
 	"github.com/rivo/tview"
 	"github.com/rs/zerolog/log"
@@ -9,6 +13,9 @@ import (
 
 type cardInputHandler interface {
 	switchToListContainerView()
+	openCardURL(id string)
+	copyCardURL(id string)
+	showCommandPrompt(a Action)
 }
 
 // CardView is a gui component in charge of displaying an open card and the list it belongs to
@@ -16,19 +23,40 @@ type CardView struct {
 	*tview.Flex
 	title       *tview.TextView
 	labels      *tview.TextView
+	dueRow      *tview.Flex
+	due         *tview.TextView
+	dueInput    *tview.InputField
 	description *tview.TextView
 
-	id      int
-	handler cardInputHandler
-	state   store.CardState
+	id         string
+	listName   string // name of the list the card belonged to when opened
+	promptsDue bool
+	handler    cardInputHandler
+	focuser    focuser
+	keymap     *Keymap
+	theme      *Theme
+	handlers   keyHandlers
+	state      store.SingleListState
+	actions    store.CardActions
 }
 
 // NewCardView returns an new instance of CardView
-func NewCardView(state store.CardState, handler cardInputHandler) *CardView {
+func NewCardView(state store.SingleListState, actions store.CardActions, keymap *Keymap, theme *Theme, handler cardInputHandler, f focuser) *CardView {
 	c := CardView{
-		id:      -1,
 		state:   state,
+		actions: actions,
 		handler: handler,
+		focuser: f,
+		keymap:  keymap,
+		theme:   theme,
+	}
+	c.handlers = keyHandlers{
+		ActionBack:              func(int) { c.handler.switchToListContainerView() },
+		ActionMoveCard:          func(int) { c.handler.showCommandPrompt(ActionMoveCard) },
+		ActionSetDue:            func(int) { c.promptDue() },
+		ActionToggleDueComplete: func(int) { c.toggleDueComplete() },
+		ActionOpenURL:           func(int) { c.handler.openCardURL(c.id) },
+		ActionCopyURL:           func(int) { c.handler.copyCardURL(c.id) },
 	}
 	root := tview.NewFlex()
 	root.SetInputCapture(c.captureInput)
@@ -46,6 +74,18 @@ func NewCardView(state store.CardState, handler cardInputHandler) *CardView {
 	labels.SetBorder(true)
 	labels.SetDynamicColors(true)
 
+	due := tview.NewTextView()
+	due.SetDynamicColors(true)
+
+	dueInput := tview.NewInputField()
+	dueInput.SetLabel("Due: ")
+	dueInput.SetPlaceholder("tomorrow 17:00, fri, +3d, 2006-01-02")
+	dueInput.SetDoneFunc(c.handleDueDone)
+
+	dueRow := tview.NewFlex()
+	dueRow.SetBorder(true)
+	dueRow.AddItem(due, 0, 1, false)
+
 	description := tview.NewTextView()
 	description.SetBorder(true)
 	description.SetInputCapture(c.captureInput)
@@ -54,46 +94,121 @@ func NewCardView(state store.CardState, handler cardInputHandler) *CardView {
 	innerF.AddItem(nil, 0, 1, false)
 	innerF.AddItem(title, 3, 1, false)
 	innerF.AddItem(labels, 3, 1, false)
+	innerF.AddItem(dueRow, 3, 1, false)
 	innerF.AddItem(description, 0, 7, true)
 	// bottom padding
 	innerF.AddItem(nil, 0, 1, false)
 	c.Flex = root
 	c.title = title
 	c.labels = labels
+	c.dueRow = dueRow
+	c.due = due
+	c.dueInput = dueInput
 	c.description = description
 	return &c
 }
 
 // FocusedItem returns the gui component currently in focus
 func (c *CardView) FocusedItem() tview.Primitive {
+	if c.promptsDue {
+		return c.dueInput
+	}
 	return c.description
 }
 
-// CardView is in charge of returning navigation to list container
-// if the id points to unexistent card id
-
-// SetState updates the CardView component with the CardState
-func (c *CardView) SetState(s store.CardState) {
+// SetState updates the CardView component with the SingleListState
+func (c *CardView) SetState(s store.SingleListState) {
 	c.state = s
 }
 
-// Draw re-implements the `tview.Primitive` interface Draw function
+// open displays the card with the provided id
+func (c *CardView) open(id string) {
+	c.id = id
+	c.listName = ""
+	if idx, found := c.state.CardList(id); found {
+		c.listName = c.state.ListName(idx)
+	}
+}
+
+// Draw re-implements the `tview.Primitive` interface Draw function. When the card is deleted
+// remotely the last known content is kept and a notice is displayed instead.
 func (c *CardView) Draw(screen tcell.Screen) {
-	c.title.SetText(c.state.CardName(c.id))
-	c.labels.SetText(c.state.CardLabelsStr(c.id))
-	c.description.SetText(c.state.Description(c.id))
+	idx, found := c.state.CardList(c.id)
+	switch {
+	case !found:
+		c.title.SetTitle(" card was deleted ")
+	case c.state.ListName(idx) != c.listName:
+		c.title.SetTitle(" card was moved to " + c.state.ListName(idx) + " ")
+	default:
+		c.title.SetTitle("")
+	}
+	c.title.SetTitleColor(c.theme.ErrorColor)
+
+	if found {
+		title := c.state.CardName(c.id)
+		if number := c.state.CardIdShort(c.id); number > 0 {
+			title = fmt.Sprintf("#%d %s", number, title)
+		}
+		c.title.SetText(title)
+		c.labels.SetText(c.theme.labelBadges(c.state.CardLabels(c.id)))
+		c.description.SetText(c.state.Description(c.id))
+	}
+	now := time.Now()
+	due, hasDue := c.state.CardDue(c.id)
+	switch {
+	case c.promptsDue:
+		c.due.SetText(c.theme.duePreview(c.dueInput.GetText(), now))
+	case !found:
+		// the last due date displayed is kept
+	case hasDue:
+		c.due.SetText(c.theme.dueBadge(due, now))
+	default:
+		c.due.SetText("No due date")
+	}
 	c.Flex.Draw(screen)
 }
 
 func (c *CardView) captureInput(event *tcell.EventKey) *tcell.EventKey {
-	switch event.Key() {
-	case tcell.KeyEsc:
-		c.handler.switchToListContainerView()
-		return event
-	case tcell.KeyEnter:
+	if c.keymap.dispatch(event, c.handlers) || event.Key() == tcell.KeyEnter {
 		return nil
 	}
 
 	log.Debug().Msg("captured input")
 	return event
 }
+
+// promptDue shows the due date input field in place of the due date
+func (c *CardView) promptDue() {
+	c.promptsDue = true
+	c.dueInput.SetText("")
+	c.dueRow.AddItem(c.dueInput, 0, 1, true)
+	c.focuser.SetFocus(c.FocusedItem())
+}
+
+// handleDueDone saves the due date entered in the input field when enter is pressed
+func (c *CardView) handleDueDone(key tcell.Key) {
+	if key == tcell.KeyEnter {
+		id, input := c.id, c.dueInput.GetText()
+		if input == "" {
+			runAction(func() error { return c.actions.SetCardDue(id, nil) })
+		} else if due, err := domain.ParseDue(input, time.Now()); err == nil {
+			runAction(func() error { return c.actions.SetCardDue(id, &due) })
+		} else {
+			// keep the prompt open until the input is valid or the prompt is cancelled
+			return
+		}
+	}
+	c.promptsDue = false
+	c.dueRow.RemoveItem(c.dueInput)
+	c.focuser.SetFocus(c.FocusedItem())
+}
+
+// toggleDueComplete marks the due date of the card as complete or not complete
+func (c *CardView) toggleDueComplete() {
+	id := c.id
+	due, found := c.state.CardDue(id)
+	if !found {
+		return
+	}
+	runAction(func() error { return c.actions.SetCardDueComplete(id, !due.Complete) })
+}
@@ -0,0 +1,181 @@
+package gui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/giannimassi/trello-tui/pkg/domain"
+)
+
+// actionArgs describes the arguments accepted by actions when executed from the command palette
+var actionArgs = map[Action]string{
+	ActionJumpList:    "<name|number>",
+	ActionAddList:     "[name]",
+	ActionRenameList:  "[name]",
+	ActionMoveCard:    "<list>",
+	ActionFilterLabel: "[label]",
+	ActionSetDue:      "[date]",
+	ActionExport:      "[path]",
+	ActionImport:      "<path>",
+}
+
+// commands returns the commands available in the command palette for the handlers provided,
+// which are the ones of the component focused when the palette was opened
+func (v *View) commands(handlers keyHandlers) []command {
+	var commands []command
+	for _, a := range v.keymap.handledActions(handlers) {
+		if a == ActionPalette {
+			continue
+		}
+		a := a
+		commands = append(commands, command{
+			name:        string(a),
+			description: actionDescriptions[a],
+			args:        actionArgs[a],
+			run: func(args string) {
+				if args != "" {
+					if _, accepted := actionArgs[a]; !accepted {
+						v.statusBar.showToast(fmt.Sprintf("%s does not accept arguments", a))
+						return
+					}
+					v.runActionWithArgs(a, args)
+					return
+				}
+				if handler := handlers[a]; handler != nil {
+					handler(0)
+				} else if handler := v.keymap.global[a]; handler != nil {
+					handler(0)
+				}
+			},
+		})
+	}
+
+	return append(commands, command{
+		name:        "board",
+		description: "switch board",
+		args:        "<name>",
+		run:         v.switchBoard,
+	})
+}
+
+// runActionWithArgs executes the action using the arguments typed in the command palette, the
+// action must be one of those accepting arguments
+func (v *View) runActionWithArgs(a Action, args string) {
+	switch a {
+	case ActionJumpList:
+		v.listContainer.selectListByName(args)
+	case ActionAddList:
+		idx := v.listContainer.viewport.focused + 1
+		runAction(func() error { return v.actions.AddList(idx, args) })
+	case ActionRenameList:
+		idx := v.listContainer.viewport.focused
+		runAction(func() error { return v.actions.RenameList(idx, args) })
+	case ActionSetDue:
+		id := v.selectedCardID()
+		due, err := domain.ParseDue(args, time.Now())
+		if err != nil {
+			v.statusBar.showToast(fmt.Sprintf("Could not set due date %q: %s", args, err))
+			return
+		}
+		runAction(func() error { return v.actions.SetCardDue(id, &due) })
+	case ActionMoveCard:
+		v.moveCard(v.selectedCardID(), args)
+	case ActionFilterLabel:
+		v.filterLabel(args)
+	case ActionExport:
+		v.exportBoard(args)
+	case ActionImport:
+		v.showImport(args, v.listContainer.viewport.focused)
+	}
+}
+
+// moveCard moves the card with the provided id to the bottom of the list whose name best matches
+// the one provided, or of the list with the provided number
+func (v *View) moveCard(id, list string) {
+	if id == "" {
+		v.statusBar.showToast("No card selected")
+		return
+	}
+	listIdx, found := v.listContainer.listByName(list)
+	if !found {
+		v.statusBar.showToast(fmt.Sprintf("No list matches %q", list))
+		return
+	}
+	listName := v.state.ListName(listIdx)
+	if from, _ := v.state.CardList(id); from == listIdx {
+		v.statusBar.showToast("The card is already in " + listName)
+		return
+	}
+	cardIdx := len(v.state.ListCardsIds(listIdx))
+	runAction(func() error { return v.actions.MoveCard(id, listIdx, cardIdx) })
+	v.statusBar.showToast(fmt.Sprintf("Moved %q to %s", v.state.CardName(id), listName))
+}
+
+// filterLabel shows only the cards with the label provided, by name or by colour if unnamed, or
+// all the cards if the label is empty
+func (v *View) filterLabel(label string) {
+	label = strings.TrimSpace(label)
+	v.listContainer.setLabelFilter(label)
+	if label == "" {
+		v.statusBar.showToast("Showing all cards")
+		return
+	}
+	v.statusBar.showToast(fmt.Sprintf("Showing cards labelled %q", label))
+}
+
+// toggleArchived shows or hides the archived cards of the board
+func (v *View) toggleArchived() {
+	runAction(func() error {
+		show, err := v.actions.ToggleArchived()
+		switch {
+		case err != nil:
+			v.queueToast("Could not show archived cards: " + err.Error())
+		case show:
+			v.queueToast("Showing archived cards")
+		default:
+			v.queueToast("Hiding archived cards")
+		}
+		return err
+	})
+}
+
+// queueToast displays the message as a toast from the gui event loop, for actions run in the background
+func (v *View) queueToast(message string) {
+	v.updater.QueueUpdateDraw(func() { v.statusBar.showToast(message) })
+}
+
+// selectedCardID returns the id of the open card or of the card selected in the focused list
+func (v *View) selectedCardID() string {
+	if v.cardFocused {
+		return v.card.id
+	}
+	return v.listContainer.focusedList().selectedID()
+}
+
+// switchBoard loads the board with the provided name in place of the current one
+func (v *View) switchBoard(name string) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return
+	}
+	if v.cardFocused {
+		v.switchToListContainerView()
+	}
+	v.listContainer.selectList(0)
+	runAction(func() error { return v.actions.SwitchBoard(name) })
+}
+
+// exportBoard writes the board to the file at the provided path, or to the default one if empty,
+// and reports the outcome
+func (v *View) exportBoard(path string) {
+	runAction(func() error {
+		path, err := v.actions.ExportBoard(strings.TrimSpace(path))
+		if err != nil {
+			v.queueToast("Could not export board: " + err.Error())
+			return err
+		}
+		v.queueToast("Board exported to " + path)
+		return nil
+	})
+}
@@ -0,0 +1,38 @@
+package gui
+
+import (
+	"time"
+
+	"github.com/gdamore/tcell"
+	"github.com/giannimassi/trello-tui/pkg/domain"
+)
+
+const dueLayout = "Mon 2 Jan 15:04"
+
+// dueBadge returns the due date formatted as a badge coloured by urgency
+func (t *Theme) dueBadge(due domain.Due, now time.Time) string {
+	var color tcell.Color
+	switch due.Status(now) {
+	case domain.DueComplete:
+		color = t.DueCompleteColor
+	case domain.DueOverdue:
+		color = t.DueOverdueColor
+	case domain.DueSoon:
+		color = t.DueSoonColor
+	default:
+		color = t.DueLaterColor
+	}
+	return t.badge(" "+due.Time.Local().Format(dueLayout)+" ", color)
+}
+
+// duePreview returns a description of the due date parsed from the input provided
+func (t *Theme) duePreview(input string, now time.Time) string {
+	if input == "" {
+		return "Remove due date"
+	}
+	due, err := domain.ParseDue(input, now)
+	if err != nil {
+		return t.colored(err.Error(), t.ErrorColor)
+	}
+	return due.Format(dueLayout + " MST")
+}
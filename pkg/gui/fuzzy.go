@@ -0,0 +1,61 @@
+package gui
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// fuzzyScore returns a score describing how well the pattern matches the text, where every rune
+// of the pattern must appear in the text in the same order. Consecutive runes and runes at the
+// start of words score higher. The second return value is false if the pattern does not match.
+func fuzzyScore(pattern, text string) (int, bool) {
+	pattern = strings.ToLower(pattern)
+	var (
+		textRunes = []rune(strings.ToLower(text))
+		score     int
+		last      = -1
+		ti        int
+	)
+	for _, pr := range pattern {
+		found := false
+		for ; ti < len(textRunes); ti++ {
+			if textRunes[ti] != pr {
+				continue
+			}
+			score++
+			if ti == last+1 {
+				score += 2
+			}
+			if ti == 0 || !unicode.IsLetter(textRunes[ti-1]) && !unicode.IsDigit(textRunes[ti-1]) {
+				score += 3
+			}
+			last = ti
+			ti++
+			found = true
+			break
+		}
+		if !found {
+			return 0, false
+		}
+	}
+	// prefer shorter texts among equivalent matches
+	return score*100 - len(textRunes), true
+}
+
+// fuzzyFilter returns the indexes of the texts matching the pattern, best matches first
+func fuzzyFilter(pattern string, texts []string) []int {
+	type match struct{ idx, score int }
+	var matches []match
+	for i, text := range texts {
+		if score, ok := fuzzyScore(pattern, text); ok {
+			matches = append(matches, match{i, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+	indexes := make([]int, len(matches))
+	for i, m := range matches {
+		indexes[i] = m.idx
+	}
+	return indexes
+}
@@ -1,7 +1,11 @@
 package gui
 
 import (
+	"os"
 	"sync"
+	"time"
+
+	"github.com/gdamore/tcell"
 
 	"github.com/giannimassi/trello-tui/pkg/store"
 	"github.com/rivo/tview"
@@ -11,9 +15,17 @@ import (
 
 // Config is the gui configuration
 type Config struct {
-	Dev bool // Enable developer features (recover on run panics)
+	Dev            bool                // Enable developer features (recover on run panics)
+	MinColumnWidth int                 // Minimum width of each list column
+	Keymap         string              // Name of the key bindings preset
+	Keys           map[string][]string // Key bindings overriding the preset, by action name
+	Theme          string              // Name of the colour theme
+	CopyFormat     string              // Template of the text copied for a card, its URL if empty
 }
 
+// headerTickInterval is how often the gui is redrawn for updating the relative times displayed
+const headerTickInterval = 30 * time.Second
+
 // Gui is a graphical user interface for trello-tui
 type Gui struct {
 	l    zerolog.Logger
@@ -21,7 +33,10 @@ type Gui struct {
 	app  *tview.Application
 	view *View
 
-	state store.GetStateFunc
+	screen tcell.Screen // screen used for running the gui, a terminal screen is used if nil
+
+	state   store.GetStateFunc
+	actions store.Actions
 
 	rw sync.RWMutex
 }
@@ -35,11 +50,33 @@ func NewGui(cfg *Config) *Gui {
 }
 
 // Init initializes the gui
-func (g *Gui) Init(getState store.GetStateFunc) error {
-	g.l.Info().Msg("Initialized")
+func (g *Gui) Init(getState store.GetStateFunc, actions store.Actions) error {
 	g.app = tview.NewApplication()
 	g.state = getState
-	g.view = NewView(g.state(), g)
+	g.actions = actions
+	keymap, err := NewKeymap(g.cfg.Keymap, g.cfg.Keys)
+	if err != nil {
+		g.l.Error().Err(err).Msg("Invalid key bindings")
+		return err
+	}
+	theme, err := NewTheme(g.cfg.Theme)
+	if err != nil {
+		g.l.Error().Err(err).Msg("Invalid theme")
+		return err
+	}
+	copyFormat, err := newCopyFormat(g.cfg.CopyFormat)
+	if err != nil {
+		g.l.Error().Err(err).Msg("Invalid copy format")
+		return err
+	}
+	// tview primitives pick their colours from the global styles when created
+	tview.Styles = theme.Theme
+	g.view = NewView(g.cfg, g.state(), g.actions, keymap, theme, copyFormat, g, g)
+	g.app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		g.actions.ReportActivity()
+		return event
+	})
+	g.l.Info().Msg("Initialized")
 	return nil
 }
 
@@ -50,13 +87,69 @@ func (g *Gui) SetFocus(p tview.Primitive) {
 	})
 }
 
+// QueueUpdateDraw implements the updater interface, executing the function in the event loop and
+// redrawing the gui
+func (g *Gui) QueueUpdateDraw(f func()) {
+	g.app.QueueUpdateDraw(f)
+}
+
+// SetScreen sets the screen used for running the gui in place of the terminal, e.g. a
+// tcell.SimulationScreen, it must be called before Run
+func (g *Gui) SetScreen(screen tcell.Screen) {
+	g.screen = screen
+}
+
+// Stop stops the event loop, making Run return
+func (g *Gui) Stop() {
+	g.app.Stop()
+}
+
 // Run executes the gui and event loop
 func (g *Gui) Run() error {
+	if g.screen == nil {
+		screen, err := tcell.NewScreen()
+		if err != nil {
+			return err
+		}
+		if err := screen.Init(); err != nil {
+			return err
+		}
+		g.screen = screen
+		// escape sequences setting the clipboard are only written to a real terminal
+		g.view.clipboard = os.Stdout
+	}
+	g.app.SetScreen(newMouseScreen(g.screen, g.handleMouse))
 	g.app.SetRoot(g.view, true)
 	g.app.SetFocus(g.view.FocusedItem())
+	stop := make(chan struct{})
+	defer close(stop)
+	go g.tick(stop)
 	return g.app.Run()
 }
 
+// tick redraws the gui periodically until stop is closed, so that the time elapsed since the
+// last refresh shown in the header stays current when the board does not change
+func (g *Gui) tick(stop <-chan struct{}) {
+	t := time.NewTicker(headerTickInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-t.C:
+			g.app.QueueUpdateDraw(func() {})
+		}
+	}
+}
+
+// handleMouse passes the mouse event to the view from the gui event loop
+func (g *Gui) handleMouse(event *tcell.EventMouse) {
+	g.actions.ReportActivity()
+	g.app.QueueUpdateDraw(func() {
+		g.view.handleMouse(event)
+	})
+}
+
 // Close handles cleanup of the gui if required
 func (g *Gui) Close() {
 	g.l.Debug().Msg("Closing")
@@ -83,4 +176,17 @@ func (g *Gui) Sync() {
 		}()
 		g.view.SetState(s)
 	})
+	// redraw once the highlight of the cards which changed has expired
+	time.AfterFunc(changeHighlightDuration, func() {
+		g.app.QueueUpdateDraw(func() {})
+	})
+}
+
+// runAction executes the action without blocking the gui event loop
+func runAction(action func() error) {
+	go func() {
+		if err := action(); err != nil {
+			log.Error().Err(err).Msg("Could not complete action")
+		}
+	}()
 }
@@ -0,0 +1,60 @@
+package guitest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// UpdateGoldenEnv is the environment variable which, when set, makes MatchGolden write the
+// snapshots instead of comparing them
+const UpdateGoldenEnv = "UPDATE_GOLDEN"
+
+// MatchGolden compares the screen content with the snapshot stored in `dir/name.golden`, it
+// returns an error describing the first difference if they do not match
+func (h *Harness) MatchGolden(dir, name string) error {
+	return MatchGolden(filepath.Join(dir, name+".golden"), h.Text())
+}
+
+// MatchGolden compares the text with the snapshot stored at the provided path, the snapshot is
+// written instead if the UpdateGoldenEnv environment variable is set
+func MatchGolden(path, text string) error {
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return errors.Wrapf(err, "could not create directory for %s", path)
+		}
+		return errors.Wrapf(ioutil.WriteFile(path, []byte(text), 0644), "could not write %s", path)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.Wrapf(err, "could not read %s, set %s=1 for creating it", path, UpdateGoldenEnv)
+	}
+	if diff := firstDifference(string(data), text); diff != "" {
+		return errors.Errorf("screen does not match %s, %s\n%s", path, diff, text)
+	}
+	return nil
+}
+
+// firstDifference describes the first line which differs between the texts, or returns an empty
+// string if they are the same
+func firstDifference(want, got string) string {
+	wantLines, gotLines := strings.Split(want, "\n"), strings.Split(got, "\n")
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		var wantLine, gotLine string
+		if i < len(wantLines) {
+			wantLine = wantLines[i]
+		}
+		if i < len(gotLines) {
+			gotLine = gotLines[i]
+		}
+		if wantLine != gotLine {
+			return fmt.Sprintf("line %d:\nwant: %q\ngot:  %q", i+1, wantLine, gotLine)
+		}
+	}
+	return ""
+}
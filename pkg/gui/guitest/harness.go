@@ -0,0 +1,211 @@
+// Package guitest drives the gui on a simulated screen, for testing the layout and the key
+// bindings without a terminal
+package guitest
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gdamore/tcell"
+	"github.com/pkg/errors"
+
+	"github.com/giannimassi/trello-tui/pkg/gui"
+	"github.com/giannimassi/trello-tui/pkg/store"
+)
+
+const (
+	// settleTimeout is the maximum time waited for the screen to stop changing
+	settleTimeout = 2 * time.Second
+	// settlePoll is the interval between two checks of the screen content
+	settlePoll = 20 * time.Millisecond
+	// settleChecks is the number of consecutive checks for which the screen must not change
+	settleChecks = 2
+)
+
+// Harness runs the gui on a tcell.SimulationScreen
+type Harness struct {
+	gui    *gui.Gui
+	screen *lockedScreen
+	done   chan error
+}
+
+// lockedScreen serializes the updates of the simulated screen content with the reads made by the
+// harness, since the cells returned by GetContents are updated in place when the gui draws
+type lockedScreen struct {
+	tcell.SimulationScreen
+	m     sync.Mutex
+	shows int // number of times the screen was drawn
+}
+
+// Show implements the tcell.Screen interface
+func (s *lockedScreen) Show() {
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.SimulationScreen.Show()
+	s.shows++
+}
+
+// drawn returns the number of times the screen was drawn
+func (s *lockedScreen) drawn() int {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.shows
+}
+
+// Sync implements the tcell.Screen interface
+func (s *lockedScreen) Sync() {
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.SimulationScreen.Sync()
+}
+
+// NewHarness returns a harness for a gui with the provided configuration, running on a simulated
+// screen of the provided size. The gui's Sync function can be used for creating the store before
+// calling Init.
+func NewHarness(cfg *gui.Config, width, height int) (*Harness, error) {
+	screen := &lockedScreen{SimulationScreen: tcell.NewSimulationScreen("UTF-8")}
+	if err := screen.Init(); err != nil {
+		return nil, errors.Wrap(err, "could not initialize simulation screen")
+	}
+	screen.SetSize(width, height)
+
+	g := gui.NewGui(cfg)
+	g.SetScreen(screen)
+	return &Harness{
+		gui:    g,
+		screen: screen,
+		done:   make(chan error, 1),
+	}, nil
+}
+
+// Init initializes the gui with the state and actions provided
+func (h *Harness) Init(getState store.GetStateFunc, actions store.Actions) error {
+	return h.gui.Init(getState, actions)
+}
+
+// Start runs the gui event loop in the background and waits for the first draw
+func (h *Harness) Start() {
+	go func() {
+		h.done <- h.gui.Run()
+	}()
+	h.Settle()
+}
+
+// Stop stops the gui event loop and returns the error it terminated with, if any
+func (h *Harness) Stop() error {
+	h.gui.Stop()
+	select {
+	case err := <-h.done:
+		return err
+	case <-time.After(settleTimeout):
+		return errors.New("gui did not stop")
+	}
+}
+
+// Gui returns the gui driven by the harness
+func (h *Harness) Gui() *gui.Gui {
+	return h.gui
+}
+
+// Press injects the keys, named as in key bindings (e.g. `j`, `Enter`, `Ctrl-P`), waiting for the
+// screen to be redrawn after each key since focus changes are applied asynchronously
+func (h *Harness) Press(keys ...string) error {
+	for _, name := range keys {
+		key, r, err := parseKey(name)
+		if err != nil {
+			return err
+		}
+		h.injectKey(key, r)
+		h.Settle()
+	}
+	return nil
+}
+
+// Type injects the text as a sequence of runes, e.g. for filling an input field
+func (h *Harness) Type(text string) {
+	for _, r := range text {
+		h.injectKey(tcell.KeyRune, r)
+	}
+	h.Settle()
+}
+
+// injectKey posts the key event, waiting for room in the event queue rather than dropping the
+// event as done by InjectKey when the queue is full, and waits for the gui to draw after handling it
+func (h *Harness) injectKey(key tcell.Key, r rune) {
+	drawn := h.screen.drawn()
+	h.screen.PostEventWait(tcell.NewEventKey(key, r, tcell.ModNone))
+	for deadline := time.Now().Add(settleTimeout); h.screen.drawn() == drawn && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// Sync pushes the latest state to the gui, as done when the state changes, and waits for the
+// screen to be redrawn
+func (h *Harness) Sync() {
+	h.gui.Sync()
+	h.Settle()
+}
+
+// Resize changes the size of the simulated screen
+func (h *Harness) Resize(width, height int) {
+	h.screen.SetSize(width, height)
+	if err := h.screen.PostEvent(tcell.NewEventResize(width, height)); err != nil {
+		return
+	}
+	h.Settle()
+}
+
+// Settle waits until the screen content is drawn and stops changing
+func (h *Harness) Settle() {
+	deadline := time.Now().Add(settleTimeout)
+	previous, stable := h.Text(), 0
+	for time.Now().Before(deadline) && stable < settleChecks {
+		time.Sleep(settlePoll)
+		text := h.Text()
+		if text == previous && strings.TrimSpace(text) != "" {
+			stable++
+		} else {
+			stable = 0
+		}
+		previous = text
+	}
+}
+
+// Text returns the content of the screen, one line per row with trailing spaces removed
+func (h *Harness) Text() string {
+	h.screen.m.Lock()
+	defer h.screen.m.Unlock()
+	cells, width, height := h.screen.GetContents()
+	var b strings.Builder
+	for y := 0; y < height; y++ {
+		var line strings.Builder
+		for x := 0; x < width; x++ {
+			runes := cells[y*width+x].Runes
+			if len(runes) == 0 {
+				line.WriteRune(' ')
+				continue
+			}
+			line.WriteRune(runes[0])
+		}
+		b.WriteString(strings.TrimRight(line.String(), " "))
+		b.WriteString("\n")
+	}
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+// parseKey returns the tcell key and rune for the key name
+func parseKey(name string) (tcell.Key, rune, error) {
+	if runes := []rune(name); len(runes) == 1 {
+		return tcell.KeyRune, runes[0], nil
+	}
+	if name == "Space" {
+		return tcell.KeyRune, ' ', nil
+	}
+	for key, keyName := range tcell.KeyNames {
+		if keyName == name {
+			return key, 0, nil
+		}
+	}
+	return 0, 0, errors.Errorf("unknown key %q", name)
+}
@@ -0,0 +1,197 @@
+package guitest_test
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/giannimassi/trello-tui/pkg/gui"
+	"github.com/giannimassi/trello-tui/pkg/gui/guitest"
+	"github.com/giannimassi/trello-tui/pkg/state"
+	"github.com/giannimassi/trello-tui/pkg/store"
+	"github.com/giannimassi/trello-tui/pkg/trello"
+	"github.com/giannimassi/trello-tui/pkg/trello/fake"
+)
+
+const (
+	testdata = "testdata"
+	// loadTimeout is the maximum time waited for the expected text to be displayed
+	loadTimeout = 5 * time.Second
+)
+
+func TestMain(m *testing.M) {
+	// due dates are displayed in the local time zone
+	time.Local = time.UTC
+	zerolog.SetGlobalLevel(zerolog.Disabled)
+	os.Exit(m.Run())
+}
+
+// demo is the gui showing the demo board served by a fake trello server
+type demo struct {
+	*guitest.Harness
+	server *fake.Server
+	stop   func()
+}
+
+// newDemo starts the gui on a screen of the provided size and waits for the demo board to be loaded
+func newDemo(t *testing.T, width, height int) *demo {
+	t.Helper()
+	server := fake.NewDemoServer()
+	trelloCfg := trello.Config{
+		User:    fake.DemoUser,
+		Key:     "key",
+		Token:   "token",
+		Timeout: time.Second,
+		BaseURL: server.APIURL(),
+	}
+	if err := fixDueDates(server, &trelloCfg); err != nil {
+		server.Close()
+		t.Fatal(err)
+	}
+
+	h, err := guitest.NewHarness(&gui.Config{MinColumnWidth: 30}, width, height)
+	if err != nil {
+		server.Close()
+		t.Fatal(err)
+	}
+	put, get := store.NewStore(h.Gui().Sync)
+	u := state.NewUpdater(&state.Config{
+		Trello:               trelloCfg,
+		SelectedBoard:        fake.DemoBoard,
+		BoardRefreshInterval: time.Minute,
+		MaxRefreshInterval:   time.Minute,
+	}, put)
+	if err := h.Init(get, u); err != nil {
+		server.Close()
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	go u.Run(ctx)
+	h.Start()
+
+	d := &demo{Harness: h, server: server}
+	d.stop = func() {
+		cancel()
+		if err := h.Stop(); err != nil {
+			t.Error(err)
+		}
+		server.Close()
+	}
+	d.waitFor(t, "11 cards")
+	return d
+}
+
+// fixDueDates replaces the due dates of the demo board, which are relative to the current time,
+// with fixed dates so that the screen does not depend on the day the tests are run
+func fixDueDates(server *fake.Server, cfg *trello.Config) error {
+	client := trello.NewClient(cfg)
+	if err := client.Init(); err != nil {
+		return err
+	}
+	board, err := client.Board(fake.DemoBoard)
+	if err != nil {
+		return err
+	}
+	for _, l := range board.Lists {
+		for _, c := range l.CardsByID {
+			if c.Due == nil {
+				continue
+			}
+			due := time.Date(2030, time.January, c.IdShort, 17, 0, 0, 0, time.UTC)
+			server.UpdateCard(c.ID, func(c *fake.Card) { c.Due = &due })
+		}
+	}
+	return nil
+}
+
+// waitFor waits until the screen contains the text
+func (d *demo) waitFor(t *testing.T, text string) {
+	t.Helper()
+	deadline := time.Now().Add(loadTimeout)
+	for !strings.Contains(d.Text(), text) {
+		if time.Now().After(deadline) {
+			t.Fatalf("screen does not contain %q:\n%s", text, d.Text())
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	d.Settle()
+}
+
+// press injects the keys and fails the test if any of them is unknown
+func (d *demo) press(t *testing.T, keys ...string) {
+	t.Helper()
+	if err := d.Press(keys...); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// match compares the screen with the snapshot with the provided name
+func (d *demo) match(t *testing.T, name string) {
+	t.Helper()
+	if err := d.MatchGolden(testdata, name); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestListNavigation(t *testing.T) {
+	d := newDemo(t, 160, 30)
+	defer d.stop()
+	d.match(t, "board")
+
+	d.press(t, "Right", "Right", "Down")
+	d.match(t, "navigation")
+
+	d.press(t, "End")
+	d.match(t, "navigation-last")
+}
+
+func TestCardView(t *testing.T) {
+	d := newDemo(t, 120, 30)
+	defer d.stop()
+
+	d.press(t, "Right", "Enter")
+	d.waitFor(t, "Happens when the session expires")
+	d.match(t, "card-open")
+
+	d.press(t, "Esc")
+	d.match(t, "card-closed")
+}
+
+func TestPalette(t *testing.T) {
+	d := newDemo(t, 160, 30)
+	defer d.stop()
+
+	d.press(t, ":")
+	d.Type("jump-list Rev")
+	d.match(t, "palette")
+
+	d.press(t, "Enter")
+	d.match(t, "palette-jump")
+}
+
+func TestOffline(t *testing.T) {
+	d := newDemo(t, 160, 30)
+	defer d.stop()
+
+	d.server.FailNext(1000, http.StatusInternalServerError)
+	d.press(t, "R")
+	d.waitFor(t, "offline")
+	d.match(t, "offline")
+}
+
+func TestResize(t *testing.T) {
+	d := newDemo(t, 160, 30)
+	defer d.stop()
+
+	d.press(t, "End")
+	d.Resize(70, 30)
+	d.match(t, "resize-narrow")
+
+	d.Resize(40, 30)
+	d.match(t, "resize-single")
+}
@@ -1,22 +1,29 @@
 package gui
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/gdamore/tcell"
 	"github.com/giannimassi/trello-tui/pkg/store"
 	"github.com/rivo/tview"
 )
 
-// Header is a gui component displaying the title and description of the current board
+// Header is a gui component displaying the title and description of the current board, together
+// with the synchronization status
 type Header struct {
 	*tview.Box
 	state store.HeaderState
+	theme *Theme
 }
 
 // NewHeader returns a new instance of Header
-func NewHeader(state store.HeaderState) *Header {
+func NewHeader(state store.HeaderState, theme *Theme) *Header {
 	return &Header{
 		Box:   tview.NewBox().SetBorder(true),
 		state: state,
+		theme: theme,
 	}
 }
 
@@ -29,4 +36,86 @@ func (h *Header) SetState(state store.HeaderState) {
 func (h *Header) Draw(screen tcell.Screen) {
 	h.SetTitle(" " + h.state.HeaderTitle() + " ")
 	h.Box.Draw(screen)
+	x, y, width, height := h.GetInnerRect()
+	if width <= 0 || height <= 0 {
+		return
+	}
+
+	status := h.status(time.Now())
+	for i := len(status); i > 0; i-- {
+		// drop the least important parts of the status until it fits
+		text := strings.Join(status[:i], " · ")
+		if textWidth := tview.TaggedStringWidth(text); textWidth < width/2 || i == 1 && textWidth < width {
+			tview.Print(screen, text, x, y, width, tview.AlignRight, h.theme.PrimaryTextColor)
+			width -= textWidth + 1
+			break
+		}
+	}
+
+	var lines []string
+	for _, line := range strings.Split(h.state.HeaderSubtitle(), "\n") {
+		lines = append(lines, tview.WordWrap(line, width)...)
+	}
+	for i := 0; i < len(lines) && i < height; i++ {
+		text := lines[i]
+		if i == height-1 && len(lines) > height {
+			// not enough space for the whole subtitle
+			text = strings.TrimRight(text, " ") + "…"
+		}
+		tview.Print(screen, tview.Escape(text), x, y+i, width, tview.AlignLeft, h.theme.SecondaryTextColor)
+	}
+}
+
+// status returns the synchronization status, the number of unread notifications, the time elapsed
+// since the last refresh and the number of lists and cards of the board, by decreasing importance
+func (h *Header) status(now time.Time) []string {
+	// the status colours follow the urgency colours used for due dates
+	var color tcell.Color
+	sync := h.state.SyncStatus()
+	switch sync {
+	case store.SyncIdle:
+		color = h.theme.DueCompleteColor
+	case store.SyncFetching:
+		color = h.theme.DueLaterColor
+	case store.SyncRetrying:
+		color = h.theme.DueSoonColor
+	default:
+		color = h.theme.DueOverdueColor
+	}
+	parts := []string{h.theme.badge(" "+sync.String()+" ", color)}
+	if unread := h.state.UnreadNotifications(); unread > 0 {
+		parts = append(parts, h.theme.colored(fmt.Sprintf("%d unread", unread), h.theme.AccentColor))
+	}
+
+	if refreshed := h.state.LastRefresh(); !refreshed.IsZero() {
+		parts = append(parts, "updated "+sinceLabel(now.Sub(refreshed)))
+		if interval := h.state.RefreshInterval(); interval > 0 {
+			parts = append(parts, "every "+intervalLabel(interval))
+		}
+		lists, cards := h.state.BoardCounts()
+		parts = append(parts, fmt.Sprintf("%d lists, %d cards", lists, cards))
+	}
+	return parts
+}
+
+// intervalLabel returns the duration without trailing zero units, e.g. `5m` instead of `5m0s`
+func intervalLabel(d time.Duration) string {
+	label := d.Round(time.Second).String()
+	if strings.HasSuffix(label, "m0s") {
+		label = strings.TrimSuffix(label, "0s")
+	}
+	return label
+}
+
+// sinceLabel returns the elapsed time in a short human readable form
+func sinceLabel(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd ago", int(d.Hours()/24))
 }
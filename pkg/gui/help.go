@@ -0,0 +1,86 @@
+package gui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell"
+	"github.com/rivo/tview"
+)
+
+const helpWidth = 60
+
+type helpHandler interface {
+	hideHelp()
+}
+
+// Help is a gui component listing every action and the keys bound to it
+type Help struct {
+	*tview.Flex
+	text     *tview.TextView
+	keymap   *Keymap
+	theme    *Theme
+	handlers keyHandlers
+}
+
+// NewHelp returns a new instance of Help
+func NewHelp(keymap *Keymap, theme *Theme, handler helpHandler) *Help {
+	text := tview.NewTextView()
+	text.SetDynamicColors(true)
+	text.SetBorder(true)
+	text.SetTitle(" Keys ")
+	h := Help{
+		Flex:   centered(text, helpWidth),
+		text:   text,
+		keymap: keymap,
+		theme:  theme,
+		handlers: keyHandlers{
+			ActionBack: func(int) { handler.hideHelp() },
+			ActionHelp: func(int) { handler.hideHelp() },
+		},
+	}
+	text.SetInputCapture(h.captureInput)
+	text.SetText(h.content())
+	return &h
+}
+
+// FocusedItem returns the gui component currently in focus
+func (h *Help) FocusedItem() tview.Primitive {
+	return h.text
+}
+
+// content returns the list of actions and their keys, generated from the keymap
+func (h *Help) content() string {
+	var b strings.Builder
+	for _, a := range actions {
+		keys := h.keymap.Keys(a)
+		labels := make([]string, len(keys))
+		for i, key := range keys {
+			labels[i] = tview.Escape(keyLabel(key))
+		}
+		fmt.Fprintf(&b, " %-22s %s\n", actionDescriptions[a], h.theme.colored(strings.Join(labels, ", "), h.theme.AccentColor))
+	}
+	if h.keymap.Counts() {
+		b.WriteString("\n Actions can be prefixed by a count, e.g. " + h.theme.colored("3j", h.theme.AccentColor) + "\n")
+	}
+	return b.String()
+}
+
+func (h *Help) captureInput(event *tcell.EventKey) *tcell.EventKey {
+	if h.keymap.dispatch(event, h.handlers) {
+		return nil
+	}
+	return event
+}
+
+// centered returns a flex displaying the primitive at the center with the provided width,
+// using most of the available height
+func centered(p tview.Primitive, width int) *tview.Flex {
+	return tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(p, 0, 8, true).
+			AddItem(nil, 0, 1, false), width, 1, true).
+		AddItem(nil, 0, 1, false)
+}
@@ -0,0 +1,168 @@
+package gui
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell"
+	"github.com/giannimassi/trello-tui/pkg/store"
+	"github.com/rivo/tview"
+)
+
+type importHandler interface {
+	hideImport()
+	importFinished(message string)
+}
+
+// ImportPreview is a gui component listing the cards read from a file before importing them
+// into a list, the import is started on confirmation and its progress displayed in the title
+type ImportPreview struct {
+	*tview.Flex
+	list     *tview.List
+	keymap   *Keymap
+	theme    *Theme
+	handler  importHandler
+	handlers keyHandlers
+	actions  store.BoardActions
+
+	status  store.ImportStatus
+	started bool // the import was confirmed and its outcome was not reported yet
+	runs    int  // runs of the plan when the import was confirmed
+}
+
+// NewImportPreview returns a new instance of ImportPreview
+func NewImportPreview(state store.ImportState, actions store.BoardActions, keymap *Keymap, theme *Theme, handler importHandler) *ImportPreview {
+	list := tview.NewList()
+	list.SetBorder(true)
+	list.SetHighlightFullLine(true)
+	list.SetMainTextColor(theme.PrimaryTextColor)
+	list.SetSecondaryTextColor(theme.SecondaryTextColor)
+	list.SetSelectedTextColor(theme.HighlightTextColor)
+	list.SetSelectedBackgroundColor(theme.HighlightBackgroundColor)
+
+	m := ImportPreview{
+		Flex:    centered(list, inboxWidth),
+		list:    list,
+		keymap:  keymap,
+		theme:   theme,
+		handler: handler,
+		actions: actions,
+		status:  state.Import(),
+	}
+	m.handlers = keyHandlers{
+		ActionBack:      func(int) { m.handler.hideImport() },
+		ActionNextCard:  func(count int) { m.selectItem(m.list.GetCurrentItem() + countOrOne(count)) },
+		ActionPrevCard:  func(count int) { m.selectItem(m.list.GetCurrentItem() - countOrOne(count)) },
+		ActionFirstCard: func(int) { m.selectItem(0) },
+		ActionLastCard:  func(int) { m.selectItem(m.list.GetItemCount() - 1) },
+	}
+	list.SetInputCapture(m.captureInput)
+	return &m
+}
+
+// FocusedItem returns the gui component currently in focus
+func (m *ImportPreview) FocusedItem() tview.Primitive {
+	return m.list
+}
+
+// SetState updates the ImportPreview component with the ImportState, and reports the outcome of
+// the import once it is done
+func (m *ImportPreview) SetState(s store.ImportState) {
+	m.status = s.Import()
+	m.refresh()
+	if !m.started || m.status.Running || m.status.Runs <= m.runs || m.status.Plan == nil {
+		return
+	}
+	m.started = false
+	plan := m.status.Plan
+	if m.status.Err != nil {
+		m.handler.importFinished(fmt.Sprintf("Import into %s interrupted after %d cards, import again to resume: %s", plan.ListName, m.status.Created, m.status.Err.Error()))
+		return
+	}
+	message := fmt.Sprintf("Imported %d cards into %s", m.status.Created, plan.ListName)
+	if missing := plan.Missing(); missing > 0 {
+		message += fmt.Sprintf(", %d labels or members not found on the board were left out", missing)
+	}
+	m.handler.importFinished(message)
+}
+
+// load reads the cards of the file at the provided path for importing them into the list at the
+// provided index
+func (m *ImportPreview) load(path string, listIdx int) {
+	m.started = false
+	m.status = store.ImportStatus{}
+	m.list.Clear()
+	m.list.SetTitle(" Import ")
+	m.list.AddItem("  Reading cards...", "", 0, nil)
+	runAction(func() error { return m.actions.PlanImport(path, listIdx) })
+}
+
+// confirm starts the import of the cards previewed
+func (m *ImportPreview) confirm() {
+	if m.started || m.status.Running || m.status.Plan == nil {
+		return
+	}
+	m.started = true
+	m.runs = m.status.Runs
+	runAction(m.actions.ImportCards)
+}
+
+// refresh fills the list with the cards of the import, keeping the current selection
+func (m *ImportPreview) refresh() {
+	plan := m.status.Plan
+	if plan == nil {
+		if m.status.Err != nil {
+			m.list.Clear()
+			m.list.SetTitle(" Import ")
+			m.list.AddItem("  Could not read cards to import", "  "+tview.Escape(m.status.Err.Error()), 0, nil)
+		}
+		return
+	}
+
+	current := m.list.GetCurrentItem()
+	m.list.Clear()
+	pending := plan.Pending()
+	switch {
+	case m.status.Running:
+		// the cards created by the current run are no longer pending
+		m.list.SetTitle(fmt.Sprintf(" Importing %d/%d cards into %s ", m.status.Created, m.status.Created+pending, tview.Escape(plan.ListName)))
+	default:
+		m.list.SetTitle(fmt.Sprintf(" Import %d cards into %s ", pending, tview.Escape(plan.ListName)))
+	}
+	for _, e := range plan.Entries() {
+		marker := m.theme.colored("+", m.theme.AccentColor) + " "
+		if e.Imported {
+			marker = m.theme.colored("✓", m.theme.DimColor) + " "
+		}
+		secondary := e.Details()
+		if e.Imported {
+			secondary = "already imported"
+		}
+		if secondary != "" {
+			secondary = "  " + tview.Escape(secondary)
+		}
+		m.list.AddItem(marker+tview.Escape(e.Card.Name), secondary, 0, nil)
+	}
+	m.selectItem(current)
+}
+
+// selectItem selects the card at the provided index, clamped to the available ones
+func (m *ImportPreview) selectItem(idx int) {
+	if idx >= m.list.GetItemCount() {
+		idx = m.list.GetItemCount() - 1
+	}
+	if idx < 0 {
+		idx = 0
+	}
+	m.list.SetCurrentItem(idx)
+}
+
+func (m *ImportPreview) captureInput(event *tcell.EventKey) *tcell.EventKey {
+	if event.Key() == tcell.KeyEnter {
+		m.confirm()
+		return nil
+	}
+	if m.keymap.dispatch(event, m.handlers) {
+		return nil
+	}
+	return event
+}
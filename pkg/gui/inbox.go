@@ -0,0 +1,119 @@
+package gui
+
+import (
+	"time"
+
+	"github.com/gdamore/tcell"
+	"github.com/giannimassi/trello-tui/pkg/notify"
+	"github.com/giannimassi/trello-tui/pkg/store"
+	"github.com/rivo/tview"
+)
+
+const inboxWidth = 80
+
+type inboxHandler interface {
+	hideInbox()
+	openBoardCard(board, id string)
+}
+
+// Inbox is a gui component listing the notifications about the cards the user cares about,
+// the notifications which were unread when the inbox was opened are marked
+type Inbox struct {
+	*tview.Flex
+	list     *tview.List
+	keymap   *Keymap
+	theme    *Theme
+	handler  inboxHandler
+	handlers keyHandlers
+
+	state  store.NotificationsState
+	events []notify.Event
+}
+
+// NewInbox returns a new instance of Inbox
+func NewInbox(state store.NotificationsState, keymap *Keymap, theme *Theme, handler inboxHandler) *Inbox {
+	list := tview.NewList()
+	list.SetBorder(true)
+	list.SetTitle(" Notifications ")
+	list.SetHighlightFullLine(true)
+	list.SetMainTextColor(theme.PrimaryTextColor)
+	list.SetSecondaryTextColor(theme.SecondaryTextColor)
+	list.SetSelectedTextColor(theme.HighlightTextColor)
+	list.SetSelectedBackgroundColor(theme.HighlightBackgroundColor)
+
+	i := Inbox{
+		Flex:    centered(list, inboxWidth),
+		list:    list,
+		keymap:  keymap,
+		theme:   theme,
+		handler: handler,
+		state:   state,
+	}
+	i.handlers = keyHandlers{
+		ActionBack:          func(int) { i.handler.hideInbox() },
+		ActionNotifications: func(int) { i.handler.hideInbox() },
+		ActionNextCard:      func(count int) { i.selectItem(i.list.GetCurrentItem() + countOrOne(count)) },
+		ActionPrevCard:      func(count int) { i.selectItem(i.list.GetCurrentItem() - countOrOne(count)) },
+		ActionFirstCard:     func(int) { i.selectItem(0) },
+		ActionLastCard:      func(int) { i.selectItem(i.list.GetItemCount() - 1) },
+		ActionOpenCard:      func(int) { i.open(i.list.GetCurrentItem()) },
+	}
+	list.SetInputCapture(i.captureInput)
+	return &i
+}
+
+// FocusedItem returns the gui component currently in focus
+func (i *Inbox) FocusedItem() tview.Primitive {
+	return i.list
+}
+
+// SetState updates the Inbox component with the NotificationsState
+func (i *Inbox) SetState(s store.NotificationsState) {
+	i.state = s
+}
+
+// refresh fills the list with the notifications, marking the first unread ones
+func (i *Inbox) refresh(unread int) {
+	i.events = i.state.Notifications()
+	i.list.Clear()
+	now := time.Now()
+	for idx, e := range i.events {
+		marker := "  "
+		if idx < unread {
+			marker = i.theme.colored("●", i.theme.AccentColor) + " "
+		}
+		secondary := "  " + e.Board + " · " + e.List + " · " + sinceLabel(now.Sub(e.Time))
+		i.list.AddItem(marker+tview.Escape(e.Message()), tview.Escape(secondary), 0, nil)
+	}
+	if len(i.events) == 0 {
+		i.list.AddItem("  No notifications", "", 0, nil)
+	}
+}
+
+// selectItem selects the notification at the provided index, clamped to the available ones
+func (i *Inbox) selectItem(idx int) {
+	if idx >= i.list.GetItemCount() {
+		idx = i.list.GetItemCount() - 1
+	}
+	if idx < 0 {
+		idx = 0
+	}
+	i.list.SetCurrentItem(idx)
+}
+
+// open displays the card of the notification at the provided index
+func (i *Inbox) open(idx int) {
+	if idx < 0 || idx >= len(i.events) {
+		return
+	}
+	e := i.events[idx]
+	i.handler.hideInbox()
+	i.handler.openBoardCard(e.Board, e.CardID)
+}
+
+func (i *Inbox) captureInput(event *tcell.EventKey) *tcell.EventKey {
+	if i.keymap.dispatch(event, i.handlers) || event.Key() == tcell.KeyEnter {
+		return nil
+	}
+	return event
+}
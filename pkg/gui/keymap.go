@@ -0,0 +1,421 @@
+package gui
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell"
+	"github.com/pkg/errors"
+)
+
+// Action is the name of a user action which can be bound to keys
+type Action string
+
+// Actions available for key bindings
+const (
+	ActionNextList            Action = "next-list"
+	ActionPrevList            Action = "prev-list"
+	ActionFirstList           Action = "first-list"
+	ActionLastList            Action = "last-list"
+	ActionJumpList            Action = "jump-list"
+	ActionNextCard            Action = "next-card"
+	ActionPrevCard            Action = "prev-card"
+	ActionFirstCard           Action = "first-card"
+	ActionLastCard            Action = "last-card"
+	ActionOpenCard            Action = "open-card"
+	ActionBack                Action = "back"
+	ActionAddList             Action = "add-list"
+	ActionRenameList          Action = "rename-list"
+	ActionArchiveList         Action = "archive-list"
+	ActionMoveListLeft        Action = "move-list-left"
+	ActionMoveListRight       Action = "move-list-right"
+	ActionMoveCard            Action = "move-card"
+	ActionFilterLabel         Action = "filter-label"
+	ActionToggleArchived      Action = "toggle-archived"
+	ActionSetDue              Action = "set-due"
+	ActionToggleDueComplete   Action = "toggle-due-complete"
+	ActionOpenURL             Action = "open-card-url"
+	ActionCopyURL             Action = "copy-card-url"
+	ActionRefresh             Action = "refresh"
+	ActionExport              Action = "export-board"
+	ActionImport              Action = "import-cards"
+	ActionNotifications       Action = "notifications"
+	ActionMemberNotifications Action = "trello-notifications"
+	ActionHelp                Action = "help"
+	ActionPalette             Action = "command-palette"
+)
+
+// actions lists all the actions in the order used for displaying them
+var actions = []Action{
+	ActionOpenCard,
+	ActionBack,
+	ActionPrevList,
+	ActionNextList,
+	ActionFirstList,
+	ActionLastList,
+	ActionJumpList,
+	ActionPrevCard,
+	ActionNextCard,
+	ActionFirstCard,
+	ActionLastCard,
+	ActionAddList,
+	ActionRenameList,
+	ActionArchiveList,
+	ActionMoveListLeft,
+	ActionMoveListRight,
+	ActionMoveCard,
+	ActionFilterLabel,
+	ActionToggleArchived,
+	ActionSetDue,
+	ActionToggleDueComplete,
+	ActionOpenURL,
+	ActionCopyURL,
+	ActionRefresh,
+	ActionExport,
+	ActionImport,
+	ActionNotifications,
+	ActionMemberNotifications,
+	ActionHelp,
+	ActionPalette,
+}
+
+// actionDescriptions describes what each action does
+var actionDescriptions = map[Action]string{
+	ActionOpenCard:            "open card",
+	ActionBack:                "back",
+	ActionPrevList:            "previous list",
+	ActionNextList:            "next list",
+	ActionFirstList:           "first list",
+	ActionLastList:            "last list",
+	ActionJumpList:            "jump to list",
+	ActionPrevCard:            "previous card",
+	ActionNextCard:            "next card",
+	ActionFirstCard:           "first card",
+	ActionLastCard:            "last card",
+	ActionAddList:             "add list",
+	ActionRenameList:          "rename list",
+	ActionArchiveList:         "archive list",
+	ActionMoveListLeft:        "move list left",
+	ActionMoveListRight:       "move list right",
+	ActionMoveCard:            "move card to list",
+	ActionFilterLabel:         "filter cards by label",
+	ActionToggleArchived:      "show or hide archived cards",
+	ActionSetDue:              "set due date",
+	ActionToggleDueComplete:   "toggle due complete",
+	ActionOpenURL:             "open card in browser",
+	ActionCopyURL:             "copy card URL",
+	ActionRefresh:             "refresh board now",
+	ActionExport:              "export board to a file",
+	ActionImport:              "import cards into the list",
+	ActionNotifications:       "notifications inbox",
+	ActionMemberNotifications: "trello notifications",
+	ActionHelp:                "help",
+	ActionPalette:             "command palette",
+}
+
+const (
+	// DefaultKeymap is the name of the default key bindings preset
+	DefaultKeymap = "default"
+	// VimKeymap is the name of the vim-like key bindings preset
+	VimKeymap = "vim"
+)
+
+// keymapPreset describes a set of key bindings
+type keymapPreset struct {
+	bindings map[Action][]string
+	counts   bool // enable numeric prefixes, e.g. `3j`
+}
+
+// common bindings shared by all presets
+var commonBindings = map[Action][]string{
+	ActionOpenCard:            {"Enter"},
+	ActionAddList:             {"a"},
+	ActionRenameList:          {"r"},
+	ActionArchiveList:         {"X"},
+	ActionMoveListLeft:        {"<"},
+	ActionMoveListRight:       {">"},
+	ActionMoveCard:            {"m"},
+	ActionFilterLabel:         {"f"},
+	ActionToggleArchived:      {"A"},
+	ActionSetDue:              {"d"},
+	ActionToggleDueComplete:   {"c"},
+	ActionOpenURL:             {"o"},
+	ActionCopyURL:             {"y"},
+	ActionRefresh:             {"R", "F5"},
+	ActionExport:              {"E"},
+	ActionImport:              {"I"},
+	ActionNotifications:       {"n"},
+	ActionMemberNotifications: {"N"},
+	ActionHelp:                {"?"},
+	ActionPalette:             {":", "Ctrl-P"},
+}
+
+var keymapPresets = map[string]keymapPreset{
+	DefaultKeymap: {
+		bindings: map[Action][]string{
+			ActionNextList:  {"Right"},
+			ActionPrevList:  {"Left"},
+			ActionFirstList: {"Home"},
+			ActionLastList:  {"End"},
+			ActionJumpList:  {"1", "2", "3", "4", "5", "6", "7", "8", "9"},
+			ActionNextCard:  {"Down", "Tab"},
+			ActionPrevCard:  {"Up", "Backtab"},
+			ActionFirstCard: {"PgUp"},
+			ActionLastCard:  {"PgDn"},
+			ActionBack:      {"Esc"},
+		},
+	},
+	VimKeymap: {
+		bindings: map[Action][]string{
+			ActionNextList:  {"l", "Right"},
+			ActionPrevList:  {"h", "Left"},
+			ActionFirstList: {"0", "Home"},
+			ActionLastList:  {"$", "End"},
+			ActionJumpList:  {"g t"},
+			ActionNextCard:  {"j", "Down"},
+			ActionPrevCard:  {"k", "Up"},
+			ActionFirstCard: {"g g"},
+			ActionLastCard:  {"G"},
+			ActionBack:      {"Esc", "q"},
+		},
+		counts: true,
+	},
+}
+
+// KeymapPresets returns the names of the available key bindings presets
+func KeymapPresets() []string {
+	names := make([]string, 0, len(keymapPresets))
+	for name := range keymapPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// keyHandlers maps the actions supported by a gui component to their handlers, count is the
+// numeric prefix typed before the key sequence or zero if no count was provided
+type keyHandlers map[Action]func(count int)
+
+// Keymap translates key events into actions. Bindings are sequences of key names separated
+// by spaces, e.g. `g g`, key names are runes (`j`, `G`, `?`) or tcell key names (`Enter`,
+// `Ctrl-P`) optionally prefixed by modifiers (`Shift+Left`).
+type Keymap struct {
+	bindings map[Action][]string
+	counts   bool
+	global   keyHandlers // handlers available regardless of the focused component
+
+	pending []string // keys typed so far in an incomplete sequence
+	count   int      // numeric prefix typed so far
+}
+
+// NewKeymap returns a new instance of Keymap using the bindings of the preset with the provided
+// name, overridden by the provided bindings
+func NewKeymap(preset string, overrides map[string][]string) (*Keymap, error) {
+	if preset == "" {
+		preset = DefaultKeymap
+	}
+	p, found := keymapPresets[preset]
+	if !found {
+		return nil, errors.Errorf("unknown keymap %q (available: %s)", preset, strings.Join(KeymapPresets(), ", "))
+	}
+
+	k := Keymap{
+		bindings: make(map[Action][]string, len(commonBindings)+len(p.bindings)),
+		counts:   p.counts,
+	}
+	for a, keys := range commonBindings {
+		k.bindings[a] = keys
+	}
+	for a, keys := range p.bindings {
+		k.bindings[a] = keys
+	}
+	for name, keys := range overrides {
+		if _, found := k.bindings[Action(name)]; !found {
+			return nil, errors.Errorf("unknown action %q in key bindings", name)
+		}
+		for _, seq := range keys {
+			for _, key := range strings.Split(seq, " ") {
+				if !validKey(key) {
+					return nil, errors.Errorf("invalid key %q in the binding %q of %s, expected a character or a key name such as Enter, Ctrl-P or Shift+Left", key, seq, name)
+				}
+			}
+		}
+		k.bindings[Action(name)] = keys
+	}
+	return &k, nil
+}
+
+// Keys returns the key sequences bound to the action
+func (k *Keymap) Keys(a Action) []string {
+	return k.bindings[a]
+}
+
+// Counts returns true if actions can be prefixed by a count
+func (k *Keymap) Counts() bool {
+	return k.counts
+}
+
+// setGlobalHandlers sets the handlers available regardless of the focused component
+func (k *Keymap) setGlobalHandlers(handlers keyHandlers) {
+	k.global = handlers
+}
+
+// handledActions returns the actions handled by the handlers provided or by the global handlers,
+// in the order used for displaying them
+func (k *Keymap) handledActions(handlers keyHandlers) []Action {
+	var handled []Action
+	for _, a := range actions {
+		if handlers[a] != nil || k.global[a] != nil {
+			handled = append(handled, a)
+		}
+	}
+	return handled
+}
+
+// dispatch resolves the key event into one of the handled actions and executes its handler.
+// It returns true if the event was consumed, either because it triggered an action or because
+// it is part of an incomplete sequence or count.
+func (k *Keymap) dispatch(event *tcell.EventKey, handlers keyHandlers) bool {
+	key := keyName(event)
+
+	if k.counts && len(k.pending) == 0 && event.Key() == tcell.KeyRune {
+		if r := event.Rune(); r >= '1' && r <= '9' || r == '0' && k.count > 0 {
+			k.count = k.count*10 + int(r-'0')
+			return true
+		}
+	}
+
+	seq := append(append([]string{}, k.pending...), key)
+	action, complete, partial := k.match(seq, handlers)
+	if !complete && !partial && len(k.pending) > 0 {
+		// the incomplete sequence was abandoned, try again with the last key only
+		seq = []string{key}
+		action, complete, partial = k.match(seq, handlers)
+	}
+
+	switch {
+	case complete:
+		count := k.count
+		if count == 0 && event.Key() == tcell.KeyRune && event.Rune() >= '1' && event.Rune() <= '9' {
+			// digit keys carry their value as count, e.g. for jumping to a list
+			count = int(event.Rune() - '0')
+		}
+		k.reset()
+		if handler := handlers[action]; handler != nil {
+			handler(count)
+		} else {
+			k.global[action](count)
+		}
+		return true
+	case partial:
+		k.pending = seq
+		return true
+	}
+	consumed := len(k.pending) > 0 || k.count > 0
+	k.reset()
+	return consumed
+}
+
+// match looks for a binding of one of the handled actions matching the sequence completely or partially
+func (k *Keymap) match(seq []string, handlers keyHandlers) (action Action, complete, partial bool) {
+	typed := strings.Join(seq, " ")
+	for _, a := range actions {
+		if handlers[a] == nil && k.global[a] == nil {
+			continue
+		}
+		for _, binding := range k.bindings[a] {
+			switch {
+			case binding == typed:
+				return a, true, false
+			case strings.HasPrefix(binding, typed+" "):
+				partial = true
+			}
+		}
+	}
+	return "", false, partial
+}
+
+// reset clears any pending sequence or count
+func (k *Keymap) reset() {
+	k.pending = nil
+	k.count = 0
+}
+
+// keyName returns the name of the key event as used in key bindings
+func keyName(event *tcell.EventKey) string {
+	var mods string
+	if event.Modifiers()&tcell.ModAlt != 0 {
+		mods += "Alt+"
+	}
+	if event.Key() == tcell.KeyRune {
+		if event.Rune() == ' ' {
+			return mods + "Space"
+		}
+		return mods + string(event.Rune())
+	}
+	if event.Modifiers()&tcell.ModShift != 0 {
+		mods = "Shift+" + mods
+	}
+	if event.Modifiers()&tcell.ModCtrl != 0 && event.Key() >= tcell.KeyUp && event.Key() <= tcell.KeyF64 {
+		mods = "Ctrl+" + mods
+	}
+	if name, found := tcell.KeyNames[event.Key()]; found {
+		return mods + name
+	}
+	return mods + string(event.Rune())
+}
+
+// keyCodes are the keys with a name, by name
+var keyCodes = func() map[string]tcell.Key {
+	codes := make(map[string]tcell.Key, len(tcell.KeyNames))
+	for key, name := range tcell.KeyNames {
+		codes[name] = key
+	}
+	return codes
+}()
+
+// validKey returns true if the key name is one of the names returned by keyName, so that the
+// bindings using it can be typed
+func validKey(name string) bool {
+	var ctrl, shift bool
+	if strings.HasPrefix(name, "Ctrl+") {
+		ctrl, name = true, strings.TrimPrefix(name, "Ctrl+")
+	}
+	if strings.HasPrefix(name, "Shift+") {
+		shift, name = true, strings.TrimPrefix(name, "Shift+")
+	}
+	name = strings.TrimPrefix(name, "Alt+")
+	if name == "Space" || utf8.RuneCountInString(name) == 1 {
+		return !ctrl && !shift
+	}
+	key, found := keyCodes[name]
+	return found && (!ctrl || key >= tcell.KeyUp && key <= tcell.KeyF64)
+}
+
+// countOrOne returns the count or 1 if no count was provided
+func countOrOne(count int) int {
+	if count < 1 {
+		return 1
+	}
+	return count
+}
+
+// keyLabels are the labels used for displaying some of the key names
+var keyLabels = map[string]string{
+	"Left":  "←",
+	"Right": "→",
+	"Up":    "↑",
+	"Down":  "↓",
+}
+
+// keyLabel returns the key sequence formatted for display
+func keyLabel(seq string) string {
+	keys := strings.Split(seq, " ")
+	for i, key := range keys {
+		if label, found := keyLabels[key]; found {
+			keys[i] = label
+		}
+	}
+	return strings.Join(keys, "")
+}
@@ -0,0 +1,88 @@
+package gui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidKey(t *testing.T) {
+	tests := map[string]bool{
+		"j":                   true,
+		"G":                   true,
+		"?":                   true,
+		"+":                   true,
+		"é":                   true,
+		"Space":               true,
+		"Alt+j":               true,
+		"Alt++":               true,
+		"Enter":               true,
+		"Ctrl-P":              true,
+		"Shift+Left":          true,
+		"Ctrl+Left":           true,
+		"Ctrl+Shift+Alt+Down": true,
+		"":                    false,
+		"jj":                  false,
+		"Ctrl+P":              false,
+		"ctl-p":               false,
+		"ctrl-p":              false,
+		"enter":               false,
+		"Shift+j":             false,
+		"Ctrl+Space":          false,
+		"Ctrl+Ctrl-P":         false,
+		"Alt+":                false,
+		"Shift+Ctrl+Left":     false,
+	}
+	for name, want := range tests {
+		if got := validKey(name); got != want {
+			t.Errorf("validKey(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestKeymapPresetsValid(t *testing.T) {
+	for _, preset := range KeymapPresets() {
+		k, err := NewKeymap(preset, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for a, bindings := range k.bindings {
+			for _, seq := range bindings {
+				for _, key := range strings.Split(seq, " ") {
+					if !validKey(key) {
+						t.Errorf("preset %s binds %s to the invalid key %q", preset, a, key)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestNewKeymapOverrides(t *testing.T) {
+	k, err := NewKeymap("", map[string][]string{string(ActionPalette): {"Ctrl-K", "g p"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys := k.Keys(ActionPalette); len(keys) != 2 || keys[0] != "Ctrl-K" || keys[1] != "g p" {
+		t.Errorf("keys = %v", keys)
+	}
+
+	tests := []struct {
+		name      string
+		overrides map[string][]string
+		want      string // text of the error
+	}{
+		{name: "unknown action", overrides: map[string][]string{"fly": {"f"}}, want: `unknown action "fly"`},
+		{name: "wrong separator", overrides: map[string][]string{string(ActionPalette): {"Ctrl+P"}}, want: `"Ctrl+P"`},
+		{name: "misspelled", overrides: map[string][]string{string(ActionPalette): {":", "ctl-p"}}, want: `"ctl-p"`},
+		{name: "empty", overrides: map[string][]string{string(ActionPalette): {""}}, want: `invalid key ""`},
+		{name: "double space", overrides: map[string][]string{string(ActionPalette): {"g  p"}}, want: `"g  p"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewKeymap("", tt.overrides)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("NewKeymap() error = %v, want %s", err, tt.want)
+			}
+		})
+	}
+}
@@ -1,52 +1,77 @@
 package gui
 
 import (
+	"strconv"
+
 	"github.com/gdamore/tcell"
 	"github.com/giannimassi/trello-tui/pkg/store"
 	"github.com/rivo/tview"
 )
 
 type switcher interface {
-	switchToCardView(id int)
+	switchToCardView(id string)
+	showCommandPrompt(a Action)
+	openCardURL(id string)
+	copyCardURL(id string)
+	filterLabel(label string)
 }
 
 // ListContainer is a gui component in charge of displaying the board's lists
 type ListContainer struct {
 	*tview.Flex
+	columns   *tview.Flex
+	tabs      *ListTabs
+	indicator *tview.TextView
 
 	focuser  focuser
 	switcher switcher
 
-	listV        []*ListView // listV is a collection of List gui components
-	state        store.ListsState
-	firstV, maxV int
-	focusedV     int
-	listALen     int
+	listV          []*ListView // listV is a collection of List gui components
+	keymap         *Keymap
+	theme          *Theme
+	state          store.ListsState
+	actions        store.ListActions
+	minColumnWidth int
+	viewport       listViewport
+	singleColumn   bool
+	scrolled       bool
+	filter         string // label of the cards displayed, all the cards are displayed if empty
 }
 
-// NewListContainer returns a new instance of ListContainer
-func NewListContainer(maxVLists int, state store.ListsState, f focuser, s switcher) *ListContainer {
+// NewListContainer returns a new instance of ListContainer, the number of lists displayed
+// depends on the available width and on the minimum width of each column
+func NewListContainer(minColumnWidth int, state store.ListsState, actions store.ListActions, keymap *Keymap, theme *Theme, f focuser, s switcher) *ListContainer {
 	var (
-		flex = tview.NewFlex().SetDirection(tview.FlexColumn)
-		ls   = ListContainer{
-			Flex:     flex,
-			focuser:  f,
-			switcher: s,
-			maxV:     maxVLists,
-			state:    state,
+		flex      = tview.NewFlex().SetDirection(tview.FlexRow)
+		columns   = tview.NewFlex().SetDirection(tview.FlexColumn)
+		indicator = tview.NewTextView().SetTextAlign(tview.AlignRight).SetTextColor(theme.DimColor)
+		ls        = ListContainer{
+			Flex:           flex,
+			columns:        columns,
+			tabs:           NewListTabs(state, theme),
+			indicator:      indicator,
+			focuser:        f,
+			switcher:       s,
+			minColumnWidth: minColumnWidth,
+			keymap:         keymap,
+			theme:          theme,
+			state:          state,
+			actions:        actions,
 		}
 	)
-	for i := 0; i < ls.maxV; i++ {
-		l := NewListView(&ls, state)
-		flex.AddItem(l, 0, 1, i == 0)
-		ls.listV = append(ls.listV, l)
+	if ls.minColumnWidth < 1 {
+		ls.minColumnWidth = 1
 	}
+	flex.AddItem(columns, 0, 1, true)
+	// a single list is displayed until the available width is known
+	ls.setVisibleLists(1)
 	return &ls
 }
 
 // SetState updates the ListContainer component with the ListState
 func (l *ListContainer) SetState(state store.ListsState) {
 	l.state = state
+	l.tabs.SetState(state)
 	// propagate state
 	for i := range l.listV {
 		l.listV[i].SetState(state)
@@ -55,49 +80,259 @@ func (l *ListContainer) SetState(state store.ListsState) {
 
 // Draw re-implements the `tview.Primitive` interface Draw function
 func (l *ListContainer) Draw(screen tcell.Screen) {
-	// log.Debug().Int("listsLen", l.state.ListsLen()).Int("focus", l.focusedV).Msg("ListContainer.Draw")
-	l.listALen = l.state.ListsLen()
-	// Check if there are less lists since last time state was set
-	if l.focusedV >= l.listALen {
-		if l.listALen == 0 {
-			l.focusedV = 0
-		} else {
-			l.focusedV = l.listALen - 1
-		}
-	}
+	l.layout()
 	// Point to right list based on navigation
 	for i := range l.listV {
-		l.listV[i].index = l.firstV + i
+		l.listV[i].index = l.viewport.first + i
 	}
+	l.tabs.selected = l.viewport.focused
+	l.indicator.SetText(l.viewport.String() + " ")
 	l.Flex.Draw(screen)
 }
 
-func (l *ListContainer) handleSelectPreviousList() {
-	if l.focusedV != 0 {
-		l.focusedV--
-		l.focuser.SetFocus(l.listV[l.focusedV].list)
-	} else if l.firstV > 0 {
-		l.firstV--
+// layout updates the number of visible lists according to the available width, falling back
+// to a single column with a tab bar listing all the lists when the width is not enough for two
+func (l *ListContainer) layout() {
+	_, _, width, _ := l.GetRect()
+	total, focused := l.state.ListsLen(), l.viewport.focusedOffset()
+	n := width / l.minColumnWidth
+	if n > total {
+		n = total
+	}
+	if n < 1 {
+		n = 1
+	}
+	l.setVisibleLists(n)
+	l.viewport.resize(n, total)
+	if l.viewport.focusedOffset() != focused {
+		l.focuser.SetFocus(l.FocusedItem())
+	}
+
+	single, scrolled := n == 1 && total > 1, l.viewport.scrolled()
+	if single != l.singleColumn || scrolled != l.scrolled {
+		l.singleColumn, l.scrolled = single, scrolled
+		l.rebuild()
+	}
+}
+
+// setVisibleLists sets the number of lists displayed side by side
+func (l *ListContainer) setVisibleLists(n int) {
+	if n == l.viewport.size {
+		return
+	}
+	for len(l.listV) < n {
+		l.listV = append(l.listV, NewListView(l, l.state, l.keymap, l.theme, l.focuser))
+	}
+	for i := 0; i < l.viewport.size; i++ {
+		l.columns.RemoveItem(l.listV[i])
+	}
+	for i := 0; i < n; i++ {
+		l.columns.AddItem(l.listV[i], 0, 1, false)
+	}
+	l.viewport.resize(n, l.viewport.total)
+}
+
+// rebuild adds the list tab bar and the scroll indicator when required
+func (l *ListContainer) rebuild() {
+	l.Flex.RemoveItem(l.tabs)
+	l.Flex.RemoveItem(l.columns)
+	l.Flex.RemoveItem(l.indicator)
+	if l.singleColumn {
+		l.Flex.AddItem(l.tabs, 1, 0, false)
+	}
+	l.Flex.AddItem(l.columns, 0, 1, true)
+	if l.scrolled {
+		l.Flex.AddItem(l.indicator, 1, 0, false)
+	}
+}
+
+// selectList focuses the list with the provided index, scrolling if required
+func (l *ListContainer) selectList(idx int) {
+	l.viewport.resize(l.viewport.size, l.state.ListsLen())
+	l.viewport.focus(idx)
+	l.focuser.SetFocus(l.FocusedItem())
+}
+
+// selectListByName focuses the list whose name best matches the one provided, or the list with
+// the provided number
+func (l *ListContainer) selectListByName(name string) {
+	if idx, found := l.listByName(name); found {
+		l.selectList(idx)
+	}
+}
+
+// listByName returns the index of the list whose name best matches the one provided, or of the
+// list with the provided number
+func (l *ListContainer) listByName(name string) (int, bool) {
+	if n, err := strconv.Atoi(name); err == nil {
+		return n - 1, n >= 1 && n <= l.state.ListsLen()
 	}
+	names := make([]string, l.state.ListsLen())
+	for i := range names {
+		names[i] = l.state.ListName(i)
+	}
+	if matches := fuzzyFilter(name, names); len(matches) > 0 {
+		return matches[0], true
+	}
+	return 0, false
 }
 
-func (l *ListContainer) handleSelectNextList() {
-	if l.focusedV < l.maxV-1 {
-		l.focusedV++
-		l.focuser.SetFocus(l.listV[l.focusedV].list)
-	} else if l.firstV+l.maxV+1 < l.listALen {
-		l.firstV++
+// setLabelFilter displays only the cards with the label provided, or all the cards if empty
+func (l *ListContainer) setLabelFilter(label string) {
+	l.filter = label
+	for _, list := range l.listV {
+		list.selectCard(0)
 	}
 }
 
-func (l *ListContainer) handleCardSelected(id int) {
+func (l *ListContainer) labelFilter() string {
+	return l.filter
+}
+
+func (l *ListContainer) handleSelectPreviousList(count int) {
+	l.selectList(l.viewport.focused - countOrOne(count))
+}
+
+func (l *ListContainer) handleSelectNextList(count int) {
+	l.selectList(l.viewport.focused + countOrOne(count))
+}
+
+func (l *ListContainer) handleSelectList(idx int) {
+	l.selectList(idx)
+}
+
+func (l *ListContainer) handleSelectLastList() {
+	l.selectList(l.state.ListsLen() - 1)
+}
+
+func (l *ListContainer) handleAddList(idx int, name string) {
+	runAction(func() error { return l.actions.AddList(idx, name) })
+}
+
+func (l *ListContainer) handleRenameList(idx int, name string) {
+	runAction(func() error { return l.actions.RenameList(idx, name) })
+}
+
+func (l *ListContainer) handleArchiveList(idx int) {
+	runAction(func() error { return l.actions.ArchiveList(idx) })
+}
+
+func (l *ListContainer) handleImport() {
+	l.switcher.showCommandPrompt(ActionImport)
+}
+
+func (l *ListContainer) handleMoveCard() {
+	l.switcher.showCommandPrompt(ActionMoveCard)
+}
+
+// handleFilterLabel prompts for the label of the cards displayed, or displays all the cards if
+// they are filtered already
+func (l *ListContainer) handleFilterLabel() {
+	if l.filter != "" {
+		l.switcher.filterLabel("")
+		return
+	}
+	l.switcher.showCommandPrompt(ActionFilterLabel)
+}
+
+func (l *ListContainer) handleOpenURL(id string) {
+	l.switcher.openCardURL(id)
+}
+
+func (l *ListContainer) handleCopyURL(id string) {
+	l.switcher.copyCardURL(id)
+}
+
+func (l *ListContainer) handleMoveList(idx, delta int) {
+	to := idx + delta
+	if to < 0 {
+		to = 0
+	}
+	if to >= l.state.ListsLen() {
+		to = l.state.ListsLen() - 1
+	}
+	if to == idx {
+		return
+	}
+	runAction(func() error { return l.actions.MoveList(idx, to) })
+	// keep the focus on the moved list
+	l.selectList(to)
+}
+
+func (l *ListContainer) handleCardSelected(id string) {
 	l.switcher.switchToCardView(id)
 }
 
+// listAt returns the visible list gui component at the provided screen position, if any
+func (l *ListContainer) listAt(x, y int) *ListView {
+	for _, list := range l.listV[:l.viewport.size] {
+		if inRect(list, x, y) {
+			return list
+		}
+	}
+	return nil
+}
+
+// cardAt returns the card displayed at the provided screen position, if any
+func (l *ListContainer) cardAt(x, y int) (*ListView, cardPosition, bool) {
+	list, pos, found := l.dropAt(x, y)
+	if !found || pos.id == "" {
+		return nil, cardPosition{}, false
+	}
+	return list, pos, true
+}
+
+// dropAt returns the position where a card dropped at the provided screen position would be
+// inserted, the id is empty when the position is past the last card of the list
+func (l *ListContainer) dropAt(x, y int) (*ListView, cardPosition, bool) {
+	list := l.listAt(x, y)
+	if list == nil {
+		return nil, cardPosition{}, false
+	}
+	idx := list.itemAt(y)
+	if idx < 0 {
+		return nil, cardPosition{}, false
+	}
+	pos := cardPosition{list: list.index, item: idx, card: idx}
+	displayed := list.cardIDs()
+	if idx < len(displayed) {
+		pos.id = displayed[idx]
+	}
+	if l.filter != "" {
+		// the cards hidden by the filter are skipped, the card is dropped before the card displayed
+		// at the position or after the last card displayed
+		all := l.state.ListCardsIds(list.index)
+		switch {
+		case pos.id != "":
+			pos.card = indexOf(all, pos.id)
+		case len(displayed) > 0:
+			pos.card = indexOf(all, displayed[len(displayed)-1]) + 1
+		default:
+			pos.card = len(all)
+		}
+	}
+	return list, pos, true
+}
+
+// indexOf returns the index of the id in ids, or -1 if not found
+func indexOf(ids []string, id string) int {
+	for i := range ids {
+		if ids[i] == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// focusedList returns the list gui component currently in focus
+func (l *ListContainer) focusedList() *ListView {
+	return l.listV[l.viewport.focusedOffset()]
+}
+
 // FocusedItem returns the currently focused list
 func (l *ListContainer) FocusedItem() tview.Primitive {
 	if len(l.listV) == 0 {
 		return nil
 	}
-	return l.listV[l.focusedV].list
+	return l.focusedList().FocusedItem()
 }
@@ -0,0 +1,80 @@
+package gui
+
+import (
+	"github.com/gdamore/tcell"
+	"github.com/giannimassi/trello-tui/pkg/store"
+	"github.com/rivo/tview"
+)
+
+const listTabsSeparator = " | "
+
+// ListTabs is a gui component displaying the names of all the board's lists on a single line,
+// it is used in place of multiple columns when the terminal is too narrow
+type ListTabs struct {
+	*tview.Box
+	state    store.ListsState
+	theme    *Theme
+	selected int
+}
+
+// NewListTabs returns a new instance of ListTabs
+func NewListTabs(state store.ListsState, theme *Theme) *ListTabs {
+	return &ListTabs{
+		Box:   tview.NewBox(),
+		state: state,
+		theme: theme,
+	}
+}
+
+// SetState updates the ListTabs component with the ListsState
+func (t *ListTabs) SetState(state store.ListsState) {
+	t.state = state
+}
+
+// Draw re-implements the `tview.Primitive` interface Draw function
+func (t *ListTabs) Draw(screen tcell.Screen) {
+	t.Box.Draw(screen)
+	x, y, width, _ := t.GetInnerRect()
+
+	var (
+		tabs          []string
+		positions     []int
+		selectedStart int
+		selectedEnd   int
+		pos           int
+	)
+	for i := 0; i < t.state.ListsLen(); i++ {
+		tab := " " + tview.Escape(t.state.ListName(i)) + " "
+		tabWidth := tview.TaggedStringWidth(tab)
+		if i == t.selected {
+			selectedStart, selectedEnd = pos, pos+tabWidth
+			tab = t.theme.badge(tab, t.theme.KeyBackgroundColor)
+		}
+		tabs = append(tabs, tab)
+		positions = append(positions, pos)
+		pos += tabWidth + len(listTabsSeparator)
+	}
+
+	// scroll horizontally so that the selected tab is visible
+	var offset int
+	if selectedEnd > width {
+		offset = selectedEnd - width
+		if offset > selectedStart {
+			offset = selectedStart
+		}
+	}
+
+	for i, tab := range tabs {
+		tabX := positions[i] - offset
+		if tabX < 0 {
+			continue
+		}
+		if tabX >= width {
+			break
+		}
+		if i > 0 && tabX >= len(listTabsSeparator) {
+			tview.Print(screen, listTabsSeparator, x+tabX-len(listTabsSeparator), y, len(listTabsSeparator), tview.AlignLeft, t.theme.DimColor)
+		}
+		tview.Print(screen, tab, x+tabX, y, width-tabX, tview.AlignLeft, t.theme.PrimaryTextColor)
+	}
+}
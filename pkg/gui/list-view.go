@@ -1,45 +1,133 @@
 package gui
 
 import (
+	"strings"
+	"time"
+
 	"github.com/gdamore/tcell"
+	"github.com/giannimassi/trello-tui/pkg/domain"
 	"github.com/giannimassi/trello-tui/pkg/store"
 	"github.com/rivo/tview"
 )
 
 type listInputHandler interface {
-	handleSelectPreviousList()
-	handleSelectNextList()
-	handleCardSelected(selectedID int)
+	handleSelectPreviousList(count int)
+	handleSelectNextList(count int)
+	handleSelectList(idx int)
+	handleSelectLastList()
+	handleCardSelected(selectedID string)
+	handleAddList(idx int, name string)
+	handleRenameList(idx int, name string)
+	handleArchiveList(idx int)
+	handleMoveList(idx, delta int)
+	handleImport()
+	handleMoveCard()
+	handleFilterLabel()
+	handleOpenURL(id string)
+	handleCopyURL(id string)
+	labelFilter() string
 }
 
+// changeHighlightDuration is how long cards changed remotely are highlighted for
+const changeHighlightDuration = 5 * time.Second
+
+// listPrompt describes the list name prompt currently shown by a ListView
+type listPrompt int
+
+const (
+	noListPrompt listPrompt = iota
+	addListPrompt
+	renameListPrompt
+)
+
 // ListView is a gui component in charge of displaying a single board list
 type ListView struct {
-	parent listInputHandler
+	parent   listInputHandler
+	focuser  focuser
+	keymap   *Keymap
+	theme    *Theme
+	handlers keyHandlers
 	*tview.Frame
-	list     *tview.List
-	index    int
-	state    store.SingleListState
-	hasFocus bool
+	content   *tview.Flex
+	list      *tview.List
+	nameInput *tview.InputField
+	prompt    listPrompt
+	index     int
+	selected  string // id of the selected card, kept selected when the cards change
+	offset    int    // index of the first card displayed
+	state     store.SingleListState
+	hasFocus  bool
 }
 
 // NewListView returns a new instance of ListView
-func NewListView(parent listInputHandler, state store.SingleListState) *ListView {
+func NewListView(parent listInputHandler, state store.SingleListState, keymap *Keymap, theme *Theme, f focuser) *ListView {
 	listView := ListView{
-		parent: parent,
-		state:  state,
+		parent:  parent,
+		focuser: f,
+		keymap:  keymap,
+		theme:   theme,
+		state:   state,
+	}
+	listView.handlers = keyHandlers{
+		ActionNextList:  parent.handleSelectNextList,
+		ActionPrevList:  parent.handleSelectPreviousList,
+		ActionFirstList: func(int) { parent.handleSelectList(0) },
+		ActionLastList:  func(int) { parent.handleSelectLastList() },
+		ActionJumpList: func(count int) {
+			if count > 0 {
+				parent.handleSelectList(count - 1)
+			}
+		},
+		ActionNextCard:      func(count int) { listView.selectCard(listView.list.GetCurrentItem() + countOrOne(count)) },
+		ActionPrevCard:      func(count int) { listView.selectCard(listView.list.GetCurrentItem() - countOrOne(count)) },
+		ActionFirstCard:     func(int) { listView.selectCard(0) },
+		ActionLastCard:      listView.handleLastCard,
+		ActionOpenCard:      func(int) { listView.handleSelected(listView.list.GetCurrentItem(), "", "", 0) },
+		ActionAddList:       func(int) { listView.promptName(addListPrompt, "") },
+		ActionRenameList:    func(int) { listView.promptName(renameListPrompt, listView.state.ListName(listView.index)) },
+		ActionArchiveList:   func(int) { parent.handleArchiveList(listView.index) },
+		ActionMoveListLeft:  func(count int) { parent.handleMoveList(listView.index, -countOrOne(count)) },
+		ActionMoveListRight: func(count int) { parent.handleMoveList(listView.index, countOrOne(count)) },
+		ActionMoveCard: func(int) {
+			if listView.selectedID() != "" {
+				parent.handleMoveCard()
+			}
+		},
+		ActionFilterLabel: func(int) { parent.handleFilterLabel() },
+		ActionImport:      func(int) { parent.handleImport() },
+		ActionOpenURL:     func(int) { listView.withSelected(parent.handleOpenURL) },
+		ActionCopyURL:     func(int) { listView.withSelected(parent.handleCopyURL) },
 	}
 	ls := tview.NewList()
 	ls.SetSelectedFocusOnly(true)
-	ls.SetShortcutColor(tcell.ColorBlack)
+	ls.SetShortcutColor(theme.PrimitiveBackgroundColor)
+	ls.SetMainTextColor(theme.PrimaryTextColor)
+	ls.SetSelectedTextColor(theme.HighlightTextColor)
+	ls.SetSelectedBackgroundColor(theme.HighlightBackgroundColor)
 	ls.SetInputCapture(listView.captureInput)
 	ls.SetSelectedFunc(listView.handleSelected)
+	ls.SetChangedFunc(listView.handleChanged)
 	listView.list = ls
-	f := tview.NewFrame(ls)
-	f.SetBorder(true)
-	listView.Frame = f
+	nameInput := tview.NewInputField()
+	nameInput.SetDoneFunc(listView.handleNameDone)
+	listView.nameInput = nameInput
+	content := tview.NewFlex().SetDirection(tview.FlexRow)
+	content.AddItem(ls, 0, 1, true)
+	listView.content = content
+	frame := tview.NewFrame(content)
+	frame.SetBorder(true)
+	listView.Frame = frame
 	return &listView
 }
 
+// FocusedItem returns the gui component currently in focus
+func (l *ListView) FocusedItem() tview.Primitive {
+	if l.prompt != noListPrompt {
+		return l.nameInput
+	}
+	return l.list
+}
+
 // SetState updates the ListView component with the SingleListState
 func (l *ListView) SetState(state store.SingleListState) {
 	l.state = state
@@ -47,15 +135,70 @@ func (l *ListView) SetState(state store.SingleListState) {
 
 // Draw re-implements the `tview.Primitive` interface Draw function
 func (l *ListView) Draw(screen tcell.Screen) {
-	l.SetTitle(" " + l.state.ListName(l.index) + " ")
-	l.updateListItems(l.state.ListCardsIds(l.index))
+	switch l.prompt {
+	case addListPrompt:
+		l.SetTitle(" New list ")
+	case renameListPrompt:
+		l.SetTitle(" Rename list ")
+	default:
+		title := " " + l.state.ListName(l.index) + " "
+		if filter := l.parent.labelFilter(); filter != "" {
+			title += "(" + filter + ") "
+		}
+		l.SetTitle(title)
+	}
+	l.updateListItems(l.cardIDs())
 	l.Frame.Draw(screen)
+	l.syncOffset()
+}
+
+// syncOffset mirrors the scrolling of the tview list, which is not exported, so that screen
+// positions can be mapped to cards
+func (l *ListView) syncOffset() {
+	_, _, _, height := l.list.GetInnerRect()
+	current := l.list.GetCurrentItem()
+	switch {
+	case current < l.offset:
+		l.offset = current
+	case 2*(current-l.offset) >= height-1:
+		l.offset = (2*current + 3 - height) / 2
+	}
 }
 
-func (l *ListView) updateListItems(cardIds []int) {
+// itemAt returns the index of the card displayed at the provided screen row, the number of cards
+// if the row is past the last card or -1 if the row is outside the list
+func (l *ListView) itemAt(y int) int {
+	_, top, _, height := l.list.GetInnerRect()
+	if y < top || y >= top+height {
+		return -1
+	}
+	// each card takes two rows, the name and the labels
+	idx := l.offset + (y-top)/2
+	if count := l.list.GetItemCount(); idx > count {
+		return count
+	}
+	return idx
+}
+
+func (l *ListView) updateListItems(cardIds []string) {
+	selected := l.selected
+	now := time.Now()
 	for i, id := range cardIds {
 		cardName := l.state.CardName(id)
-		cardLabels := l.state.CardLabelsStr(id) + "\n\n"
+		if l.state.CardArchived(id) {
+			cardName = l.theme.colored("archived ", l.theme.DimColor) + cardName
+		}
+		if changedAt := l.state.CardChangedAt(id); now.Sub(changedAt) < changeHighlightDuration {
+			cardName = l.theme.colored("● ", l.theme.AccentColor) + cardName
+		}
+		cardLabels := l.theme.labelBadges(l.state.CardLabels(id))
+		if due, found := l.state.CardDue(id); found {
+			if cardLabels != "" {
+				cardLabels += " "
+			}
+			cardLabels += l.theme.dueBadge(due, now)
+		}
+		cardLabels += "\n\n"
 		// Add new list items
 		if i >= l.list.GetItemCount() {
 			l.list.AddItem(cardName, " ", ' ', nil)
@@ -64,46 +207,132 @@ func (l *ListView) updateListItems(cardIds []int) {
 		if oldTitle, oldLbls := l.list.GetItemText(i); oldTitle != cardName || oldLbls != cardLabels {
 			l.list.SetItemText(i, cardName, cardLabels)
 		}
-		// Remove deleted list items
-		for i := l.list.GetItemCount() - 1; i >= len(cardIds); i-- {
-			l.list.RemoveItem(i)
+	}
+	// Remove deleted list items
+	for i := l.list.GetItemCount() - 1; i >= len(cardIds); i-- {
+		l.list.RemoveItem(i)
+	}
+	// Keep the selected card selected wherever it moved, items are updated by index
+	for i, id := range cardIds {
+		if id == selected {
+			l.list.SetCurrentItem(i)
+			break
 		}
 	}
+	l.handleChanged(l.list.GetCurrentItem(), "", "", 0)
+}
+
+// handleChanged tracks the id of the selected card
+func (l *ListView) handleChanged(index int, _, _ string, _ rune) {
+	if cardIDs := l.cardIDs(); index < len(cardIDs) {
+		l.selected = cardIDs[index]
+	}
 }
 
 func (l *ListView) captureInput(event *tcell.EventKey) *tcell.EventKey {
-	switch event.Key() {
-	// intercept and pass to parent (list container):
-	// - left/right keys: navigate to next or previous list
-	case tcell.KeyLeft:
-		l.parent.handleSelectPreviousList()
-		return nil
-	case tcell.KeyRight:
-		l.parent.handleSelectNextList()
+	if l.keymap.dispatch(event, l.handlers) {
 		return nil
 	}
 	// let default handler of the handle all other keys as well for now
 	return event
 }
 
+// selectCard selects the card at the provided index, clamped to the list's cards
+func (l *ListView) selectCard(index int) {
+	if index >= l.list.GetItemCount() {
+		index = l.list.GetItemCount() - 1
+	}
+	if index < 0 {
+		index = 0
+	}
+	l.list.SetCurrentItem(index)
+}
+
+// handleLastCard selects the last card, or the card with the provided count if any
+func (l *ListView) handleLastCard(count int) {
+	if count > 0 {
+		l.selectCard(count - 1)
+		return
+	}
+	l.selectCard(l.list.GetItemCount() - 1)
+}
+
+// promptName shows the list name input field above the list's cards
+func (l *ListView) promptName(prompt listPrompt, name string) {
+	if l.prompt == noListPrompt {
+		l.content.RemoveItem(l.list)
+		l.content.AddItem(l.nameInput, 1, 0, true)
+		l.content.AddItem(l.list, 0, 1, false)
+	}
+	l.prompt = prompt
+	l.nameInput.SetText(name)
+	l.focuser.SetFocus(l.FocusedItem())
+}
+
+// handleNameDone creates or renames the list when enter is pressed
+func (l *ListView) handleNameDone(key tcell.Key) {
+	name := strings.TrimSpace(l.nameInput.GetText())
+	if key == tcell.KeyEnter && name != "" {
+		switch l.prompt {
+		case addListPrompt:
+			l.parent.handleAddList(l.index+1, name)
+		case renameListPrompt:
+			if name != l.state.ListName(l.index) {
+				l.parent.handleRenameList(l.index, name)
+			}
+		}
+	}
+	l.prompt = noListPrompt
+	l.content.RemoveItem(l.nameInput)
+	l.content.RemoveItem(l.list)
+	l.content.AddItem(l.list, 0, 1, true)
+	l.focuser.SetFocus(l.FocusedItem())
+}
+
 func (l *ListView) handleSelected(index int, _, _ string, _ rune) {
-	l.parent.handleCardSelected(l.selectedIndexToID(index))
+	if id := l.selectedIndexToID(index); id != "" {
+		l.parent.handleCardSelected(id)
+	}
+}
+
+// withSelected calls handle with the id of the selected card, if any
+func (l *ListView) withSelected(handle func(id string)) {
+	if id := l.selectedID(); id != "" {
+		handle(id)
+	}
 }
 
-func (l *ListView) selectedID() int {
+func (l *ListView) selectedID() string {
 	current := l.list.GetCurrentItem()
-	cardIDs := l.state.ListCardsIds(l.index)
+	cardIDs := l.cardIDs()
 	if current >= len(cardIDs) {
-		return -1
+		return ""
 	}
 	return cardIDs[current]
 }
 
-func (l *ListView) selectedIndexToID(index int) int {
+func (l *ListView) selectedIndexToID(index int) string {
 	current := l.list.GetCurrentItem()
-	cardIDs := l.state.ListCardsIds(l.index)
+	cardIDs := l.cardIDs()
 	if current >= len(cardIDs) {
-		return -1
+		return ""
 	}
 	return cardIDs[current]
 }
+
+// cardIDs returns the ids of the cards displayed, the cards of the list with the label of the
+// filter if any
+func (l *ListView) cardIDs() []string {
+	cardIDs := l.state.ListCardsIds(l.index)
+	filter := l.parent.labelFilter()
+	if filter == "" {
+		return cardIDs
+	}
+	var filtered []string
+	for _, id := range cardIDs {
+		if matched, _ := domain.MatchLabels([]string{filter}, l.state.CardLabels(id)); len(matched) > 0 {
+			filtered = append(filtered, id)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,79 @@
+package gui
+
+import "fmt"
+
+// listViewport describes which of the board's lists are visible and which one is focused,
+// all indexes refer to the board's lists
+type listViewport struct {
+	first   int // first visible list
+	size    int // number of visible lists
+	focused int // focused list
+	total   int // number of lists in the board
+}
+
+// last returns the index of the last visible list
+func (v *listViewport) last() int {
+	return v.first + v.size - 1
+}
+
+// focusedOffset returns the position of the focused list among the visible ones
+func (v *listViewport) focusedOffset() int {
+	return v.focused - v.first
+}
+
+// resize updates the number of visible and total lists, keeping the focused list visible
+func (v *listViewport) resize(size, total int) {
+	if size < 1 {
+		size = 1
+	}
+	if total < 0 {
+		total = 0
+	}
+	v.size, v.total = size, total
+	v.focus(v.focused)
+}
+
+// focus sets the focused list, scrolling the minimum amount required to keep it visible
+func (v *listViewport) focus(idx int) {
+	if idx >= v.total {
+		idx = v.total - 1
+	}
+	if idx < 0 {
+		idx = 0
+	}
+	v.focused = idx
+
+	if v.focused < v.first {
+		v.first = v.focused
+	}
+	if v.focused > v.last() {
+		v.first = v.focused - v.size + 1
+	}
+	// avoid empty columns when there are enough lists to fill them
+	if v.first+v.size > v.total {
+		v.first = v.total - v.size
+	}
+	if v.first < 0 {
+		v.first = 0
+	}
+}
+
+// scrolled returns true if some of the lists are not visible
+func (v *listViewport) scrolled() bool {
+	return v.total > v.size
+}
+
+// String returns a description of the visible lists such as `lists 4–6 of 11`
+func (v *listViewport) String() string {
+	if v.total == 0 {
+		return "no lists"
+	}
+	last := v.last()
+	if last >= v.total {
+		last = v.total - 1
+	}
+	if v.first == last {
+		return fmt.Sprintf("list %d of %d", v.first+1, v.total)
+	}
+	return fmt.Sprintf("lists %d–%d of %d", v.first+1, last+1, v.total)
+}
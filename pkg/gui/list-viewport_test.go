@@ -0,0 +1,42 @@
+package gui
+
+import "testing"
+
+func TestListViewport(t *testing.T) {
+	tests := []struct {
+		name        string
+		size, total int
+		focus       int
+		resize      int // size after focusing, unchanged if zero
+		first       int
+		focused     int
+		description string
+	}{
+		{name: "fewer lists than columns", size: 5, total: 3, focus: 2, first: 0, focused: 2, description: "lists 1–3 of 3"},
+		{name: "as many lists as columns", size: 3, total: 3, focus: 1, first: 0, focused: 1, description: "lists 1–3 of 3"},
+		{name: "no lists", size: 3, total: 0, focus: 2, first: 0, focused: 0, description: "no lists"},
+		{name: "single column", size: 1, total: 11, focus: 4, first: 4, focused: 4, description: "list 5 of 11"},
+		{name: "scroll right", size: 3, total: 11, focus: 5, first: 3, focused: 5, description: "lists 4–6 of 11"},
+		{name: "focus past the last list", size: 3, total: 11, focus: 20, first: 8, focused: 10, description: "lists 9–11 of 11"},
+		{name: "focus before the first list", size: 3, total: 11, focus: -3, first: 0, focused: 0, description: "lists 1–3 of 11"},
+		{name: "shrink keeps the focused list visible", size: 5, total: 11, focus: 7, resize: 2, first: 6, focused: 7, description: "lists 7–8 of 11"},
+		{name: "grow fills the columns", size: 3, total: 11, focus: 10, resize: 5, first: 6, focused: 10, description: "lists 7–11 of 11"},
+		{name: "shrink to nothing keeps one column", size: 3, total: 11, focus: 5, resize: -1, first: 5, focused: 5, description: "list 6 of 11"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v listViewport
+			v.resize(tt.size, tt.total)
+			v.focus(tt.focus)
+			if tt.resize != 0 {
+				v.resize(tt.resize, tt.total)
+			}
+			if v.first != tt.first || v.focused != tt.focused {
+				t.Errorf("first, focused = %d, %d, want %d, %d", v.first, v.focused, tt.first, tt.focused)
+			}
+			if got := v.String(); got != tt.description {
+				t.Errorf("String() = %q, want %q", got, tt.description)
+			}
+		})
+	}
+}
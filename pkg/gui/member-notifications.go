@@ -0,0 +1,163 @@
+package gui
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell"
+	"github.com/giannimassi/trello-tui/pkg/domain"
+	"github.com/giannimassi/trello-tui/pkg/store"
+	"github.com/rivo/tview"
+)
+
+// notificationTextWidth is the maximum width of the comment text displayed below a notification
+const notificationTextWidth = 50
+
+type memberNotificationsHandler interface {
+	hideMemberNotifications()
+	openBoardCard(board, id string)
+}
+
+// MemberNotifications is a gui component listing the trello notifications of the user, e.g.
+// mentions, unread notifications are marked and opening one marks it as read
+type MemberNotifications struct {
+	*tview.Flex
+	list     *tview.List
+	keymap   *Keymap
+	theme    *Theme
+	handler  memberNotificationsHandler
+	handlers keyHandlers
+	actions  store.NotificationActions
+
+	state         store.MemberNotificationsState
+	notifications []domain.Notification
+}
+
+// NewMemberNotifications returns a new instance of MemberNotifications
+func NewMemberNotifications(state store.MemberNotificationsState, actions store.NotificationActions, keymap *Keymap, theme *Theme, handler memberNotificationsHandler) *MemberNotifications {
+	list := tview.NewList()
+	list.SetBorder(true)
+	list.SetTitle(" Trello notifications ")
+	list.SetHighlightFullLine(true)
+	list.SetMainTextColor(theme.PrimaryTextColor)
+	list.SetSecondaryTextColor(theme.SecondaryTextColor)
+	list.SetSelectedTextColor(theme.HighlightTextColor)
+	list.SetSelectedBackgroundColor(theme.HighlightBackgroundColor)
+
+	m := MemberNotifications{
+		Flex:    centered(list, inboxWidth),
+		list:    list,
+		keymap:  keymap,
+		theme:   theme,
+		handler: handler,
+		actions: actions,
+		state:   state,
+	}
+	m.handlers = keyHandlers{
+		ActionBack:                func(int) { m.handler.hideMemberNotifications() },
+		ActionMemberNotifications: func(int) { m.handler.hideMemberNotifications() },
+		ActionNextCard:            func(count int) { m.selectItem(m.list.GetCurrentItem() + countOrOne(count)) },
+		ActionPrevCard:            func(count int) { m.selectItem(m.list.GetCurrentItem() - countOrOne(count)) },
+		ActionFirstCard:           func(int) { m.selectItem(0) },
+		ActionLastCard:            func(int) { m.selectItem(m.list.GetItemCount() - 1) },
+		ActionOpenCard:            func(int) { m.open(m.list.GetCurrentItem()) },
+	}
+	list.SetInputCapture(m.captureInput)
+	return &m
+}
+
+// FocusedItem returns the gui component currently in focus
+func (m *MemberNotifications) FocusedItem() tview.Primitive {
+	return m.list
+}
+
+// SetState updates the MemberNotifications component with the MemberNotificationsState
+func (m *MemberNotifications) SetState(s store.MemberNotificationsState) {
+	m.state = s
+	m.refresh()
+}
+
+// load fetches the latest notifications, the ones loaded previously are displayed meanwhile
+func (m *MemberNotifications) load() {
+	m.refresh()
+	m.selectItem(0)
+	runAction(m.actions.LoadMemberNotifications)
+}
+
+// refresh fills the list with the notifications, keeping the current selection
+func (m *MemberNotifications) refresh() {
+	current := m.list.GetCurrentItem()
+	m.notifications = m.state.MemberNotifications()
+	m.list.Clear()
+	now := time.Now()
+	for _, n := range m.notifications {
+		marker := "  "
+		if n.Unread {
+			marker = m.theme.colored("●", m.theme.AccentColor) + " "
+		}
+		secondary := "  " + n.BoardName + " · " + sinceLabel(now.Sub(n.Date))
+		if n.Text != "" {
+			secondary += " · " + truncate(n.Text, notificationTextWidth)
+		}
+		m.list.AddItem(marker+tview.Escape(n.Message()), tview.Escape(secondary), 0, nil)
+	}
+
+	err := m.state.MemberNotificationsErr()
+	switch {
+	case len(m.notifications) > 0:
+	case err != nil:
+		m.list.AddItem("  Could not load notifications", "  "+tview.Escape(err.Error()), 0, nil)
+	case m.notifications == nil:
+		m.list.AddItem("  Loading...", "", 0, nil)
+	default:
+		m.list.AddItem("  No notifications", "", 0, nil)
+	}
+	m.selectItem(current)
+}
+
+// selectItem selects the notification at the provided index, clamped to the available ones
+func (m *MemberNotifications) selectItem(idx int) {
+	if idx >= m.list.GetItemCount() {
+		idx = m.list.GetItemCount() - 1
+	}
+	if idx < 0 {
+		idx = 0
+	}
+	m.list.SetCurrentItem(idx)
+}
+
+// open marks the notification at the provided index as read and displays its board and card
+func (m *MemberNotifications) open(idx int) {
+	if idx < 0 || idx >= len(m.notifications) {
+		return
+	}
+	n := m.notifications[idx]
+	if n.Unread {
+		runAction(func() error { return m.actions.MarkMemberNotificationRead(n.ID) })
+	}
+	if n.BoardName == "" {
+		return
+	}
+	m.handler.hideMemberNotifications()
+	m.handler.openBoardCard(n.BoardName, n.CardID)
+}
+
+func (m *MemberNotifications) captureInput(event *tcell.EventKey) *tcell.EventKey {
+	if m.keymap.dispatch(event, m.handlers) || event.Key() == tcell.KeyEnter {
+		return nil
+	}
+	return event
+}
+
+// truncate returns the first line of the text, shortened with an ellipsis to fit the width
+func truncate(text string, width int) string {
+	line := strings.SplitN(strings.TrimSpace(text), "\n", 2)
+	runes := []rune(line[0])
+	if len(runes) <= width && len(line) == 1 {
+		return line[0]
+	}
+	if len(runes) > width-1 {
+		runes = runes[:width-1]
+	}
+	return strings.TrimRight(string(runes), " ") + "…"
+}
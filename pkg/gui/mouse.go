@@ -0,0 +1,142 @@
+package gui
+
+import (
+	"time"
+
+	"github.com/gdamore/tcell"
+	"github.com/rivo/tview"
+)
+
+// doubleClickInterval is the maximum time between two clicks on the same card for opening it
+const doubleClickInterval = 400 * time.Millisecond
+
+// mouseScreen wraps a tcell.Screen and intercepts mouse events, since tview does not dispatch
+// them to its primitives
+type mouseScreen struct {
+	tcell.Screen
+	handle func(event *tcell.EventMouse)
+}
+
+// newMouseScreen returns a screen with mouse reporting enabled which passes mouse events to the
+// handler provided
+func newMouseScreen(screen tcell.Screen, handle func(event *tcell.EventMouse)) *mouseScreen {
+	screen.EnableMouse()
+	return &mouseScreen{
+		Screen: screen,
+		handle: handle,
+	}
+}
+
+// PollEvent returns the next event which is not a mouse event
+func (s *mouseScreen) PollEvent() tcell.Event {
+	for {
+		event := s.Screen.PollEvent()
+		mouse, ok := event.(*tcell.EventMouse)
+		if !ok {
+			return event
+		}
+		s.handle(mouse)
+	}
+}
+
+// cardPosition identifies a card displayed in the list container
+type cardPosition struct {
+	list int // index of the list in the board
+	card int // index of the card in the list
+	item int // index of the card among the ones displayed, which can be filtered
+	id   string
+}
+
+// mouseState tracks the mouse buttons across events, for detecting double clicks and drags
+type mouseState struct {
+	pressed   bool
+	pressedOn *cardPosition // card under the pointer when the button was pressed, nil if none
+	lastClick time.Time
+	lastCard  string
+}
+
+// handleMouse selects, opens and moves cards and lists depending on the mouse event
+func (v *View) handleMouse(event *tcell.EventMouse) {
+	if v.helpVisible || v.paletteVisible || v.inboxVisible || v.notificationsVisible || v.importVisible {
+		return
+	}
+	x, y := event.Position()
+	buttons := event.Buttons()
+
+	switch {
+	case buttons&tcell.Button1 != 0 && !v.mouse.pressed:
+		v.mouse.pressed = true
+		v.mouse.pressedOn = nil
+		if inRect(v.header, x, y) {
+			v.showBoardSwitcher()
+			return
+		}
+		if v.cardFocused {
+			return
+		}
+		list, pos, found := v.listContainer.cardAt(x, y)
+		if !found {
+			return
+		}
+		v.listContainer.selectList(pos.list)
+		list.selectCard(pos.item)
+		v.mouse.pressedOn = &pos
+
+		now := time.Now()
+		if pos.id == v.mouse.lastCard && now.Sub(v.mouse.lastClick) < doubleClickInterval {
+			v.mouse.lastClick = time.Time{}
+			v.switchToCardView(pos.id)
+			return
+		}
+		v.mouse.lastClick = now
+		v.mouse.lastCard = pos.id
+
+	case buttons == tcell.ButtonNone && v.mouse.pressed:
+		v.mouse.pressed = false
+		from := v.mouse.pressedOn
+		if from == nil || v.cardFocused {
+			return
+		}
+		_, to, found := v.listContainer.dropAt(x, y)
+		if !found {
+			return
+		}
+		// the dragged card is removed from its list before being inserted, shifting the cards below it
+		if to.list == from.list && to.card > from.card {
+			to.card--
+		}
+		if to.list == from.list && to.card == from.card {
+			return
+		}
+		v.mouse.lastClick = time.Time{}
+		runAction(func() error { return v.actions.MoveCard(from.id, to.list, to.card) })
+		v.listContainer.selectList(to.list)
+
+	case buttons&tcell.WheelUp != 0, buttons&tcell.WheelDown != 0:
+		if v.cardFocused {
+			return
+		}
+		list := v.listContainer.listAt(x, y)
+		if list == nil {
+			return
+		}
+		delta := 1
+		if buttons&tcell.WheelUp != 0 {
+			delta = -1
+		}
+		v.listContainer.selectList(list.index)
+		list.selectCard(list.list.GetCurrentItem() + delta)
+
+	case buttons&tcell.WheelLeft != 0 && !v.cardFocused:
+		v.listContainer.handleSelectPreviousList(1)
+
+	case buttons&tcell.WheelRight != 0 && !v.cardFocused:
+		v.listContainer.handleSelectNextList(1)
+	}
+}
+
+// inRect returns true if the position is within the primitive
+func inRect(p tview.Primitive, x, y int) bool {
+	rectX, rectY, width, height := p.GetRect()
+	return x >= rectX && x < rectX+width && y >= rectY && y < rectY+height
+}
@@ -0,0 +1,153 @@
+package gui
+
+import (
+	"strings"
+
+	"github.com/gdamore/tcell"
+	"github.com/rivo/tview"
+)
+
+const paletteWidth = 70
+
+// command is an operation which can be executed from the command palette
+type command struct {
+	name        string
+	description string
+	args        string // description of the arguments accepted, empty if none
+	run         func(args string)
+}
+
+type paletteHandler interface {
+	hidePalette()
+}
+
+// Palette is a gui component for executing commands by name, commands are fuzzy matched
+// against the first word typed and the rest of the input is passed as arguments
+type Palette struct {
+	*tview.Flex
+	input   *tview.InputField
+	list    *tview.List
+	handler paletteHandler
+	theme   *Theme
+
+	commands []command
+	matches  []int
+}
+
+// NewPalette returns a new instance of Palette
+func NewPalette(theme *Theme, handler paletteHandler) *Palette {
+	p := Palette{
+		handler: handler,
+		theme:   theme,
+	}
+	input := tview.NewInputField()
+	input.SetLabel(":")
+	input.SetPlaceholder("command [arguments]")
+	input.SetChangedFunc(p.handleChanged)
+	input.SetDoneFunc(p.handleDone)
+	input.SetInputCapture(p.captureInput)
+
+	list := tview.NewList()
+	list.ShowSecondaryText(false)
+	list.SetHighlightFullLine(true)
+	list.SetMainTextColor(theme.PrimaryTextColor)
+	list.SetSelectedTextColor(theme.HighlightTextColor)
+	list.SetSelectedBackgroundColor(theme.HighlightBackgroundColor)
+
+	content := tview.NewFlex().SetDirection(tview.FlexRow)
+	content.SetBorder(true)
+	content.SetTitle(" Commands ")
+	content.AddItem(input, 1, 0, true)
+	content.AddItem(list, 0, 1, false)
+
+	p.Flex = centered(content, paletteWidth)
+	p.input = input
+	p.list = list
+	return &p
+}
+
+// FocusedItem returns the gui component currently in focus
+func (p *Palette) FocusedItem() tview.Primitive {
+	return p.input
+}
+
+// SetCommands resets the palette with the commands provided
+func (p *Palette) SetCommands(commands []command) {
+	p.commands = commands
+	p.input.SetText("")
+	p.handleChanged("")
+}
+
+// parseCommand splits the input into the command name and its arguments
+func parseCommand(input string) (string, string) {
+	input = strings.TrimPrefix(strings.TrimSpace(input), ":")
+	fields := strings.SplitN(input, " ", 2)
+	if len(fields) < 2 {
+		return fields[0], ""
+	}
+	return fields[0], strings.TrimSpace(fields[1])
+}
+
+func (p *Palette) handleChanged(text string) {
+	name, _ := parseCommand(text)
+	texts := make([]string, len(p.commands))
+	for i, c := range p.commands {
+		texts[i] = c.name + " " + c.description
+	}
+	p.matches = fuzzyFilter(name, texts)
+
+	p.list.Clear()
+	for _, idx := range p.matches {
+		c := p.commands[idx]
+		item := c.name
+		if c.args != "" {
+			item += " " + c.args
+		}
+		p.list.AddItem(tview.Escape(item)+"  "+p.theme.colored(c.description, p.theme.DimColor), "", 0, nil)
+	}
+}
+
+func (p *Palette) handleDone(key tcell.Key) {
+	if key != tcell.KeyEnter {
+		p.handler.hidePalette()
+		return
+	}
+
+	name, args := parseCommand(p.input.GetText())
+	c, found := p.selected(name)
+	if !found {
+		return
+	}
+	p.handler.hidePalette()
+	c.run(args)
+}
+
+// selected returns the command with the provided name or, if there is none, the highlighted match
+func (p *Palette) selected(name string) (command, bool) {
+	for _, c := range p.commands {
+		if c.name == name {
+			return c, true
+		}
+	}
+	current := p.list.GetCurrentItem()
+	if current < 0 || current >= len(p.matches) {
+		return command{}, false
+	}
+	return p.commands[p.matches[current]], true
+}
+
+func (p *Palette) captureInput(event *tcell.EventKey) *tcell.EventKey {
+	switch event.Key() {
+	case tcell.KeyDown, tcell.KeyTab, tcell.KeyCtrlN:
+		if current := p.list.GetCurrentItem(); current < p.list.GetItemCount()-1 {
+			p.list.SetCurrentItem(current + 1)
+		}
+		return nil
+	case tcell.KeyUp, tcell.KeyBacktab, tcell.KeyCtrlP:
+		if current := p.list.GetCurrentItem(); current > 0 {
+			p.list.SetCurrentItem(current - 1)
+		}
+		return nil
+	}
+	return event
+}
@@ -0,0 +1,85 @@
+package gui
+
+import (
+	"time"
+
+	"github.com/gdamore/tcell"
+	"github.com/rivo/tview"
+)
+
+// StatusBar is a gui component listing the keys available for the focused component
+type StatusBar struct {
+	*tview.Box
+	keymap    *Keymap
+	theme     *Theme
+	handled   []Action
+	prompting bool
+
+	toast      string    // transient message displayed on the right
+	toastUntil time.Time // time at which the toast is hidden
+}
+
+// toastDuration is how long toasts are displayed for, the gui is redrawn once the highlight of the
+// changed cards expires so toasts must not outlast it
+const toastDuration = changeHighlightDuration
+
+// NewStatusBar returns a new instance of StatusBar
+func NewStatusBar(keymap *Keymap, theme *Theme) *StatusBar {
+	return &StatusBar{
+		Box:    tview.NewBox(),
+		keymap: keymap,
+		theme:  theme,
+	}
+}
+
+// SetContext updates the actions displayed by the status bar
+func (s *StatusBar) SetContext(handled []Action, prompting bool) {
+	s.handled = handled
+	s.prompting = prompting
+}
+
+// showToast displays the message for a few seconds
+func (s *StatusBar) showToast(message string) {
+	s.toast = message
+	s.toastUntil = time.Now().Add(toastDuration)
+}
+
+// Draw re-implements the `tview.Primitive` interface Draw function
+func (s *StatusBar) Draw(screen tcell.Screen) {
+	s.Box.Draw(screen)
+	x, y, width, _ := s.GetInnerRect()
+	if s.toast != "" && time.Now().Before(s.toastUntil) {
+		toast := s.theme.badge(" "+tview.Escape(s.toast)+" ", s.theme.AccentColor)
+		tview.Print(screen, toast, x, y, width, tview.AlignRight, s.theme.PrimaryTextColor)
+		if toastWidth := tview.TaggedStringWidth(toast); toastWidth < width {
+			width -= toastWidth + 1
+		} else {
+			return
+		}
+	}
+	if s.prompting {
+		hints := s.keyHint("Enter", "confirm") + s.keyHint("Esc", "cancel")
+		tview.Print(screen, hints, x, y, width, tview.AlignLeft, s.theme.PrimaryTextColor)
+		return
+	}
+
+	for _, a := range s.handled {
+		keys := s.keymap.Keys(a)
+		if len(keys) == 0 {
+			continue
+		}
+		hint := s.keyHint(keyLabel(keys[0]), actionDescriptions[a])
+		hintWidth := tview.TaggedStringWidth(hint)
+		if hintWidth > width {
+			break
+		}
+		tview.Print(screen, hint, x, y, width, tview.AlignLeft, s.theme.PrimaryTextColor)
+		x += hintWidth
+		width -= hintWidth
+	}
+}
+
+// keyHint returns the key formatted as a badge followed by its description
+func (s *StatusBar) keyHint(key, description string) string {
+	return s.theme.badge(" "+tview.Escape(key)+" ", s.theme.KeyBackgroundColor) + " " + description + "  "
+}
@@ -0,0 +1,276 @@
+package gui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell"
+	"github.com/giannimassi/trello-tui/pkg/domain"
+	"github.com/pkg/errors"
+	"github.com/rivo/tview"
+)
+
+// Theme describes the colours used by the gui components
+type Theme struct {
+	tview.Theme // base colours applied to all tview primitives
+
+	HighlightBackgroundColor tcell.Color // background of selected items
+	HighlightTextColor       tcell.Color // text of selected items
+	BadgeTextColor           tcell.Color // text of labels, due dates and key hints
+	KeyBackgroundColor       tcell.Color // background of key hints and selected tabs
+	AccentColor              tcell.Color // text which should stand out, e.g. keys in the help
+	DimColor                 tcell.Color // text which should stand back, e.g. separators
+	ErrorColor               tcell.Color // error messages
+
+	DueLaterColor    tcell.Color
+	DueSoonColor     tcell.Color
+	DueOverdueColor  tcell.Color
+	DueCompleteColor tcell.Color
+
+	LabelColors map[string]tcell.Color // background of labels by trello colour name
+	Monochrome  bool                   // badges are displayed in reverse instead of using colours
+}
+
+const (
+	// DarkTheme is the name of the default theme, for terminals with a dark background
+	DarkTheme = "dark"
+	// LightTheme is the name of the theme for terminals with a light background
+	LightTheme = "light"
+	// HighContrastTheme is the name of the theme maximising contrast
+	HighContrastTheme = "high-contrast"
+	// BasicTheme is the name of the theme using only the 16 basic terminal colours
+	BasicTheme = "16-color"
+	// MonochromeTheme is the name of the theme using only the terminal's default colours
+	MonochromeTheme = "monochrome"
+)
+
+// trelloLabelColors maps trello label colours to true colours
+var trelloLabelColors = map[string]tcell.Color{
+	"green":  tcell.NewHexColor(0x61bd4f),
+	"yellow": tcell.NewHexColor(0xf2d600),
+	"orange": tcell.NewHexColor(0xff9f1a),
+	"red":    tcell.NewHexColor(0xeb5a46),
+	"purple": tcell.NewHexColor(0xc377e0),
+	"blue":   tcell.NewHexColor(0x0079bf),
+	"sky":    tcell.NewHexColor(0x00c2e0),
+	"lime":   tcell.NewHexColor(0x51e898),
+	"pink":   tcell.NewHexColor(0xff78cb),
+	"black":  tcell.NewHexColor(0x344563),
+}
+
+// basicLabelColors maps trello label colours to the 16 basic terminal colours
+var basicLabelColors = map[string]tcell.Color{
+	"green":  tcell.ColorGreen,
+	"yellow": tcell.ColorYellow,
+	"orange": tcell.ColorOlive,
+	"red":    tcell.ColorRed,
+	"purple": tcell.ColorPurple,
+	"blue":   tcell.ColorBlue,
+	"sky":    tcell.ColorAqua,
+	"lime":   tcell.ColorLime,
+	"pink":   tcell.ColorFuchsia,
+	"black":  tcell.ColorGray,
+}
+
+var themes = map[string]Theme{
+	DarkTheme: {
+		Theme:                    tview.Styles,
+		HighlightBackgroundColor: tcell.ColorWhite,
+		HighlightTextColor:       tcell.ColorBlack,
+		BadgeTextColor:           tcell.ColorBlack,
+		KeyBackgroundColor:       tcell.ColorWhite,
+		AccentColor:              tcell.ColorYellow,
+		DimColor:                 tcell.ColorGray,
+		ErrorColor:               tcell.ColorRed,
+		DueLaterColor:            tcell.ColorWhite,
+		DueSoonColor:             tcell.ColorYellow,
+		DueOverdueColor:          tcell.ColorRed,
+		DueCompleteColor:         tcell.ColorGreen,
+		LabelColors:              trelloLabelColors,
+	},
+	LightTheme: {
+		Theme: tview.Theme{
+			PrimitiveBackgroundColor:    tcell.ColorWhite,
+			ContrastBackgroundColor:     tcell.ColorLightGray,
+			MoreContrastBackgroundColor: tcell.ColorSilver,
+			BorderColor:                 tcell.ColorDarkGray,
+			TitleColor:                  tcell.ColorBlack,
+			GraphicsColor:               tcell.ColorDarkGray,
+			PrimaryTextColor:            tcell.ColorBlack,
+			SecondaryTextColor:          tcell.ColorNavy,
+			TertiaryTextColor:           tcell.ColorDarkGreen,
+			InverseTextColor:            tcell.ColorWhite,
+			ContrastSecondaryTextColor:  tcell.ColorDarkBlue,
+		},
+		HighlightBackgroundColor: tcell.ColorNavy,
+		HighlightTextColor:       tcell.ColorWhite,
+		BadgeTextColor:           tcell.ColorBlack,
+		KeyBackgroundColor:       tcell.ColorLightGray,
+		AccentColor:              tcell.ColorNavy,
+		DimColor:                 tcell.ColorDarkGray,
+		ErrorColor:               tcell.ColorDarkRed,
+		DueLaterColor:            tcell.ColorLightGray,
+		DueSoonColor:             tcell.ColorGold,
+		DueOverdueColor:          tcell.ColorTomato,
+		DueCompleteColor:         tcell.ColorLightGreen,
+		LabelColors:              trelloLabelColors,
+	},
+	HighContrastTheme: {
+		Theme: tview.Theme{
+			PrimitiveBackgroundColor:    tcell.ColorBlack,
+			ContrastBackgroundColor:     tcell.ColorWhite,
+			MoreContrastBackgroundColor: tcell.ColorYellow,
+			BorderColor:                 tcell.ColorYellow,
+			TitleColor:                  tcell.ColorWhite,
+			GraphicsColor:               tcell.ColorYellow,
+			PrimaryTextColor:            tcell.ColorWhite,
+			SecondaryTextColor:          tcell.ColorWhite,
+			TertiaryTextColor:           tcell.ColorAqua,
+			InverseTextColor:            tcell.ColorBlack,
+			ContrastSecondaryTextColor:  tcell.ColorBlack,
+		},
+		HighlightBackgroundColor: tcell.ColorYellow,
+		HighlightTextColor:       tcell.ColorBlack,
+		BadgeTextColor:           tcell.ColorBlack,
+		KeyBackgroundColor:       tcell.ColorYellow,
+		AccentColor:              tcell.ColorAqua,
+		DimColor:                 tcell.ColorWhite,
+		ErrorColor:               tcell.ColorRed,
+		DueLaterColor:            tcell.ColorWhite,
+		DueSoonColor:             tcell.ColorYellow,
+		DueOverdueColor:          tcell.ColorRed,
+		DueCompleteColor:         tcell.ColorLime,
+		LabelColors:              basicLabelColors,
+	},
+	BasicTheme: {
+		Theme: tview.Theme{
+			PrimitiveBackgroundColor:    tcell.ColorBlack,
+			ContrastBackgroundColor:     tcell.ColorNavy,
+			MoreContrastBackgroundColor: tcell.ColorGreen,
+			BorderColor:                 tcell.ColorSilver,
+			TitleColor:                  tcell.ColorWhite,
+			GraphicsColor:               tcell.ColorSilver,
+			PrimaryTextColor:            tcell.ColorWhite,
+			SecondaryTextColor:          tcell.ColorYellow,
+			TertiaryTextColor:           tcell.ColorGreen,
+			InverseTextColor:            tcell.ColorNavy,
+			ContrastSecondaryTextColor:  tcell.ColorTeal,
+		},
+		HighlightBackgroundColor: tcell.ColorSilver,
+		HighlightTextColor:       tcell.ColorBlack,
+		BadgeTextColor:           tcell.ColorBlack,
+		KeyBackgroundColor:       tcell.ColorSilver,
+		AccentColor:              tcell.ColorYellow,
+		DimColor:                 tcell.ColorGray,
+		ErrorColor:               tcell.ColorRed,
+		DueLaterColor:            tcell.ColorSilver,
+		DueSoonColor:             tcell.ColorYellow,
+		DueOverdueColor:          tcell.ColorRed,
+		DueCompleteColor:         tcell.ColorGreen,
+		LabelColors:              basicLabelColors,
+	},
+	MonochromeTheme: {
+		Theme: tview.Theme{
+			PrimitiveBackgroundColor:    tcell.ColorDefault,
+			ContrastBackgroundColor:     tcell.ColorDefault,
+			MoreContrastBackgroundColor: tcell.ColorDefault,
+			BorderColor:                 tcell.ColorDefault,
+			TitleColor:                  tcell.ColorDefault,
+			GraphicsColor:               tcell.ColorDefault,
+			PrimaryTextColor:            tcell.ColorDefault,
+			SecondaryTextColor:          tcell.ColorDefault,
+			TertiaryTextColor:           tcell.ColorDefault,
+			InverseTextColor:            tcell.ColorDefault,
+			ContrastSecondaryTextColor:  tcell.ColorDefault,
+		},
+		HighlightBackgroundColor: tcell.ColorWhite,
+		HighlightTextColor:       tcell.ColorBlack,
+		BadgeTextColor:           tcell.ColorDefault,
+		KeyBackgroundColor:       tcell.ColorDefault,
+		AccentColor:              tcell.ColorDefault,
+		DimColor:                 tcell.ColorDefault,
+		ErrorColor:               tcell.ColorDefault,
+		DueLaterColor:            tcell.ColorDefault,
+		DueSoonColor:             tcell.ColorDefault,
+		DueOverdueColor:          tcell.ColorDefault,
+		DueCompleteColor:         tcell.ColorDefault,
+		Monochrome:               true,
+	},
+}
+
+// Themes returns the names of the available themes
+func Themes() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewTheme returns the theme with the provided name
+func NewTheme(name string) (*Theme, error) {
+	if name == "" {
+		name = DarkTheme
+	}
+	t, found := themes[name]
+	if !found {
+		return nil, errors.Errorf("unknown theme %q (available: %s)", name, strings.Join(Themes(), ", "))
+	}
+	return &t, nil
+}
+
+// colorNames are the names of the palette colours, the first one in alphabetical order for the
+// colours with more than one name, e.g. `gray` and `grey`
+var colorNames = func() map[tcell.Color]string {
+	names := make(map[tcell.Color]string, len(tcell.ColorNames))
+	for name, c := range tcell.ColorNames {
+		if current, found := names[c]; !found || name < current {
+			names[c] = name
+		}
+	}
+	return names
+}()
+
+// colorTag returns the colour formatted for tview colour tags, palette colours are referred to by
+// name so that the terminal displays them with its own palette, as it does for the theme colours
+func colorTag(c tcell.Color) string {
+	if c == tcell.ColorDefault {
+		return "-"
+	}
+	if name, found := colorNames[c]; found && c&tcell.ColorIsRGB == 0 {
+		return name
+	}
+	return fmt.Sprintf("#%06x", c.Hex())
+}
+
+// badge returns the text formatted with the provided background colour, or in reverse for monochrome themes
+func (t *Theme) badge(text string, background tcell.Color) string {
+	if t.Monochrome {
+		return "[::r]" + text + "[::-]"
+	}
+	return "[" + colorTag(t.BadgeTextColor) + ":" + colorTag(background) + "]" + text + "[-:-:-]"
+}
+
+// colored returns the text formatted with the provided foreground colour
+func (t *Theme) colored(text string, c tcell.Color) string {
+	return "[" + colorTag(c) + "]" + text + "[-]"
+}
+
+// labelColor returns the background colour for the trello label colour provided
+func (t *Theme) labelColor(trelloColor string) tcell.Color {
+	if c, found := t.LabelColors[trelloColor]; found {
+		return c
+	}
+	return t.KeyBackgroundColor
+}
+
+// labelBadges returns the labels formatted as badges coloured like on trello
+func (t *Theme) labelBadges(labels []domain.CardLabel) string {
+	badges := make([]string, len(labels))
+	for i, lbl := range labels {
+		badges[i] = t.badge(" "+tview.Escape(lbl.Name)+" ", t.labelColor(lbl.Color))
+	}
+	return strings.Join(badges, " ")
+}
@@ -0,0 +1,26 @@
+package gui
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell"
+)
+
+func TestColorTag(t *testing.T) {
+	tests := []struct {
+		color tcell.Color
+		want  string
+	}{
+		{color: tcell.ColorDefault, want: "-"},
+		{color: tcell.ColorRed, want: "red"},
+		{color: tcell.ColorGray, want: "gray"},
+		{color: tcell.NewRGBColor(0x12, 0x34, 0x56), want: "#123456"},
+		// RGB colours are kept as hex values even when they match a named colour
+		{color: tcell.NewHexColor(0xff0000), want: "#ff0000"},
+	}
+	for _, tt := range tests {
+		if got := colorTag(tt.color); got != tt.want {
+			t.Errorf("colorTag(%v) = %q, want %q", tt.color, got, tt.want)
+		}
+	}
+}
@@ -1,6 +1,13 @@
 package gui
 
 import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/gdamore/tcell"
 	"github.com/giannimassi/trello-tui/pkg/store"
 	"github.com/rivo/tview"
 	"github.com/rs/zerolog/log"
@@ -8,78 +15,404 @@ import (
 
 // View is the root gui component
 type View struct {
-	*tview.Flex
+	*tview.Pages
+	body          *tview.Flex
 	header        *Header
 	listContainer *ListContainer
 	card          *CardView
+	statusBar     *StatusBar
+	help          *Help
+	palette       *Palette
+	inbox         *Inbox
+	notifications *MemberNotifications
+	importPreview *ImportPreview
 
-	focuser     focuser
-	cardFocused bool
+	focuser              focuser
+	updater              updater
+	keymap               *Keymap
+	copyFormat           *template.Template // template of the text copied for a card
+	clipboard            io.Writer          // terminal receiving the copied text, nil if not available
+	actions              store.Actions
+	cardFocused          bool
+	helpVisible          bool
+	paletteVisible       bool
+	inboxVisible         bool
+	notificationsVisible bool
+	importVisible        bool
+	mouse                mouseState
+	state                store.ViewState
+	pendingCard          string    // card to be opened once the board containing it is loaded
+	pendingSince         time.Time // time at which the pending card was requested
+	lastToast            time.Time // time of the newest notification displayed as toast
 }
 
 type focuser interface {
 	SetFocus(p tview.Primitive)
 }
 
+// updater executes functions in the gui event loop, e.g. for reporting the outcome of the actions
+// run in the background
+type updater interface {
+	QueueUpdateDraw(f func())
+}
+
 const (
-	headerHeight = 1
-	bodyHeight   = 6
+	headerHeight    = 4
+	bodyHeight      = 6
+	statusBarHeight = 1
+
+	mainPage          = "main"
+	helpPage          = "help"
+	palettePage       = "palette"
+	inboxPage         = "inbox"
+	notificationsPage = "notifications"
+	importPage        = "import"
 )
 
 // NewView returns a new instance of View
-func NewView(state store.ViewState, f focuser) *View {
+func NewView(cfg *Config, state store.ViewState, actions store.Actions, keymap *Keymap, theme *Theme, copyFormat *template.Template, f focuser, u updater) *View {
 	var (
 		v = View{
-			focuser: f,
+			focuser:    f,
+			updater:    u,
+			keymap:     keymap,
+			copyFormat: copyFormat,
+			actions:    actions,
+			state:      state,
 		}
-		header        = NewHeader(state)
-		listContainer = NewListContainer(3, state, f, &v)
-		card          = NewCardView(state, &v)
-		flex          = tview.NewFlex().
+		header        = NewHeader(state, theme)
+		listContainer = NewListContainer(cfg.MinColumnWidth, state, actions, keymap, theme, f, &v)
+		card          = NewCardView(state, actions, keymap, theme, &v, f)
+		statusBar     = NewStatusBar(keymap, theme)
+		help          = NewHelp(keymap, theme, &v)
+		palette       = NewPalette(theme, &v)
+		inbox         = NewInbox(state, keymap, theme, &v)
+		notifications = NewMemberNotifications(state, actions, keymap, theme, &v)
+		importPreview = NewImportPreview(state, actions, keymap, theme, &v)
+		body          = tview.NewFlex().
 				SetFullScreen(true).
 				SetDirection(tview.FlexRow).
-				AddItem(header, 0, headerHeight, false).
-				AddItem(listContainer, 0, bodyHeight, false)
+				AddItem(header, headerHeight, 0, false).
+				AddItem(listContainer, 0, bodyHeight, false).
+				AddItem(statusBar, statusBarHeight, 0, false)
+		pages = tview.NewPages().
+			AddPage(mainPage, body, true, true).
+			AddPage(helpPage, help, true, false).
+			AddPage(palettePage, palette, true, false).
+			AddPage(inboxPage, inbox, true, false).
+			AddPage(notificationsPage, notifications, true, false).
+			AddPage(importPage, importPreview, true, false)
 	)
-	v.Flex = flex
+	v.Pages = pages
+	v.body = body
 	v.header = header
 	v.listContainer = listContainer
 	v.card = card
+	v.statusBar = statusBar
+	v.help = help
+	v.palette = palette
+	v.inbox = inbox
+	v.notifications = notifications
+	v.importPreview = importPreview
+	keymap.setGlobalHandlers(keyHandlers{
+		ActionRefresh:             func(int) { v.actions.RequestRefresh() },
+		ActionExport:              func(int) { v.exportBoard("") },
+		ActionToggleArchived:      func(int) { v.toggleArchived() },
+		ActionNotifications:       func(int) { v.showInbox() },
+		ActionMemberNotifications: func(int) { v.showMemberNotifications() },
+		ActionHelp:                func(int) { v.showHelp() },
+		ActionPalette:             func(int) { v.showPalette() },
+	})
 	return &v
 }
 
 // SetState updates the View with the ViewState
 func (v *View) SetState(s store.ViewState) {
+	v.state = s
 	v.header.SetState(s)
 	v.listContainer.SetState(s)
 	v.card.SetState(s)
+	v.inbox.SetState(s)
+	v.notifications.SetState(s)
+	v.importPreview.SetState(s)
+	v.toastNotifications()
+	if v.pendingCard != "" {
+		if _, found := s.CardList(v.pendingCard); found {
+			v.switchToCardView(v.pendingCard)
+			v.pendingCard = ""
+		} else if s.LastRefresh().After(v.pendingSince) {
+			// the board was loaded without the card
+			v.pendingCard = ""
+		}
+	}
+}
+
+// toastNotifications displays the notifications received since the last toast
+func (v *View) toastNotifications() {
+	var fresh int
+	notifications := v.state.Notifications()
+	for _, e := range notifications {
+		if !e.Time.After(v.lastToast) {
+			break
+		}
+		fresh++
+	}
+	if fresh == 0 {
+		return
+	}
+	v.lastToast = notifications[0].Time
+	message := notifications[0].Message()
+	if fresh > 1 {
+		message += fmt.Sprintf(" (+%d more)", fresh-1)
+	}
+	v.statusBar.showToast(message)
+}
+
+// Draw re-implements the `tview.Primitive` interface Draw function
+func (v *View) Draw(screen tcell.Screen) {
+	handled := v.keymap.handledActions(v.focusedHandlers())
+	switch {
+	case v.paletteVisible, v.importVisible:
+		v.statusBar.SetContext(nil, true)
+	case v.helpVisible, v.inboxVisible, v.notificationsVisible:
+		v.statusBar.SetContext(handled, false)
+	case v.cardFocused:
+		v.statusBar.SetContext(handled, v.card.promptsDue)
+	default:
+		v.statusBar.SetContext(handled, v.listContainer.focusedList().prompt != noListPrompt)
+	}
+	v.Pages.Draw(screen)
 }
 
 // FocusedItem returns the gui component currently in focus
 // TODO: this is mainly necessary to ensure the focus is on the correct
 // item at startup, this is not nice.
 func (v *View) FocusedItem() tview.Primitive {
+	if v.paletteVisible {
+		return v.palette.FocusedItem()
+	}
+	if v.helpVisible {
+		return v.help.FocusedItem()
+	}
+	if v.inboxVisible {
+		return v.inbox.FocusedItem()
+	}
+	if v.notificationsVisible {
+		return v.notifications.FocusedItem()
+	}
+	if v.importVisible {
+		return v.importPreview.FocusedItem()
+	}
 	if v.cardFocused {
 		return v.card.FocusedItem()
 	}
 	return v.listContainer.FocusedItem()
 }
 
-func (v *View) switchToCardView(id int) {
-	log.Debug().Int("id", id).Msg("switching to card view")
-	// remove list container
-	v.RemoveItem(v.listContainer)
-	// add card view
-	v.card.id = id
-	v.AddItem(v.card, 0, bodyHeight, true)
+// setBody replaces the component displayed between the header and the status bar
+func (v *View) setBody(remove, add tview.Primitive) {
+	v.body.RemoveItem(remove)
+	v.body.RemoveItem(v.statusBar)
+	v.body.AddItem(add, 0, bodyHeight, true)
+	v.body.AddItem(v.statusBar, statusBarHeight, 0, false)
+}
+
+func (v *View) switchToCardView(id string) {
+	log.Debug().Str("id", id).Msg("switching to card view")
+	v.card.open(id)
+	v.setBody(v.listContainer, v.card)
 	v.cardFocused = true
 	v.focuser.SetFocus(v.FocusedItem())
 }
 
 func (v *View) switchToListContainerView() {
-	// remove list container
-	v.RemoveItem(v.card)
-	v.AddItem(v.listContainer, 0, bodyHeight, true)
+	v.setBody(v.card, v.listContainer)
 	v.cardFocused = false
 	v.focuser.SetFocus(v.FocusedItem())
 }
+
+// focusedHandlers returns the key handlers of the focused component
+func (v *View) focusedHandlers() keyHandlers {
+	switch {
+	case v.helpVisible:
+		return v.help.handlers
+	case v.inboxVisible:
+		return v.inbox.handlers
+	case v.notificationsVisible:
+		return v.notifications.handlers
+	case v.importVisible:
+		return v.importPreview.handlers
+	case v.cardFocused:
+		return v.card.handlers
+	}
+	return v.listContainer.focusedList().handlers
+}
+
+func (v *View) showPalette() {
+	v.palette.SetCommands(v.commands(v.focusedHandlers()))
+	v.paletteVisible = true
+	v.ShowPage(palettePage)
+	v.focuser.SetFocus(v.FocusedItem())
+}
+
+// showBoardSwitcher shows the command palette ready for typing the name of the board to switch to
+func (v *View) showBoardSwitcher() {
+	v.showPalette()
+	v.palette.input.SetText("board ")
+}
+
+func (v *View) hidePalette() {
+	v.paletteVisible = false
+	v.HidePage(palettePage)
+	v.focuser.SetFocus(v.FocusedItem())
+}
+
+func (v *View) showHelp() {
+	v.helpVisible = true
+	v.ShowPage(helpPage)
+	v.focuser.SetFocus(v.FocusedItem())
+}
+
+func (v *View) hideHelp() {
+	v.helpVisible = false
+	v.HidePage(helpPage)
+	v.focuser.SetFocus(v.FocusedItem())
+}
+
+// showInbox shows the notifications inbox and marks the notifications as read
+func (v *View) showInbox() {
+	v.hideOverlays()
+	v.inbox.refresh(v.state.UnreadNotifications())
+	v.inboxVisible = true
+	v.ShowPage(inboxPage)
+	v.focuser.SetFocus(v.FocusedItem())
+	runAction(func() error {
+		v.actions.MarkNotificationsRead()
+		return nil
+	})
+}
+
+func (v *View) hideInbox() {
+	v.inboxVisible = false
+	v.HidePage(inboxPage)
+	v.focuser.SetFocus(v.FocusedItem())
+}
+
+// showMemberNotifications shows the trello notifications of the user and loads the latest ones
+func (v *View) showMemberNotifications() {
+	v.hideOverlays()
+	v.notifications.load()
+	v.notificationsVisible = true
+	v.ShowPage(notificationsPage)
+	v.focuser.SetFocus(v.FocusedItem())
+}
+
+func (v *View) hideMemberNotifications() {
+	v.notificationsVisible = false
+	v.HidePage(notificationsPage)
+	v.focuser.SetFocus(v.FocusedItem())
+}
+
+// showCommandPrompt shows the command palette ready for typing the arguments of the action, e.g.
+// the path of the file to import
+func (v *View) showCommandPrompt(a Action) {
+	v.showPalette()
+	v.palette.input.SetText(string(a) + " ")
+}
+
+// showImport shows the preview of the import of the file at the provided path into the list at
+// the provided index
+func (v *View) showImport(path string, listIdx int) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return
+	}
+	v.hideOverlays()
+	v.importPreview.load(path, listIdx)
+	v.importVisible = true
+	v.ShowPage(importPage)
+	v.focuser.SetFocus(v.FocusedItem())
+}
+
+func (v *View) hideImport() {
+	v.importVisible = false
+	v.HidePage(importPage)
+	v.focuser.SetFocus(v.FocusedItem())
+}
+
+// importFinished reports the outcome of the import, hiding its preview
+func (v *View) importFinished(message string) {
+	if v.importVisible {
+		v.hideImport()
+	}
+	v.statusBar.showToast(message)
+}
+
+// openCardURL opens the card with the provided id in the browser
+func (v *View) openCardURL(id string) {
+	url := v.state.CardURL(id)
+	if url == "" {
+		v.statusBar.showToast("The card has no URL")
+		return
+	}
+	if err := openURL(url); err != nil {
+		log.Error().Err(err).Str("url", url).Msg("Could not open card URL")
+		v.statusBar.showToast("Could not open the browser: " + err.Error())
+		return
+	}
+	v.statusBar.showToast("Opened " + url)
+}
+
+// copyCardURL copies the text of the card with the provided id, by default its URL, to the clipboard
+func (v *View) copyCardURL(id string) {
+	text, err := cardText(v.copyFormat, v.state, v.state.ListName, id)
+	switch {
+	case err != nil:
+		log.Error().Err(err).Msg("Could not format copied card")
+		v.statusBar.showToast(err.Error())
+		return
+	case text == "":
+		v.statusBar.showToast("Nothing to copy, the card has no URL")
+		return
+	case v.clipboard == nil:
+		v.statusBar.showToast("The clipboard is not available")
+		return
+	}
+	if err := copyToClipboard(v.clipboard, text); err != nil {
+		log.Error().Err(err).Msg("Could not copy card")
+		v.statusBar.showToast(err.Error())
+		return
+	}
+	v.statusBar.showToast("Copied " + text)
+}
+
+// hideOverlays hides the help, notifications and import pages, for showing another one in their place
+func (v *View) hideOverlays() {
+	if v.helpVisible {
+		v.hideHelp()
+	}
+	if v.inboxVisible {
+		v.hideInbox()
+	}
+	if v.notificationsVisible {
+		v.hideMemberNotifications()
+	}
+	if v.importVisible {
+		v.hideImport()
+	}
+}
+
+// openBoardCard opens the card with the corresponding id, switching to the board with the
+// provided name first if the card is not part of the current board
+func (v *View) openBoardCard(board, id string) {
+	if v.cardFocused {
+		v.switchToListContainerView()
+	}
+	if _, found := v.state.CardList(id); found {
+		v.switchToCardView(id)
+		return
+	}
+	v.switchBoard(board)
+	v.pendingCard = id
+	v.pendingSince = time.Now()
+}
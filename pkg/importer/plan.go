@@ -0,0 +1,207 @@
+package importer
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/giannimassi/trello-tui/pkg/domain"
+)
+
+// dateLayout is the layout of due dates in the details of the cards
+const dateLayout = "2006-01-02 15:04"
+
+// Entry is a card of an import
+type Entry struct {
+	Card     domain.Card
+	Unknown  []string // names of the labels not found on the board, which are left out
+	Imported bool     // the card was created, by Run or by a previous attempt at the import which was interrupted
+	Missing  int      // number of labels and members left out of the card created since they were not found
+}
+
+// Details describes the labels, members and due date of the card, and the labels left out
+func (e Entry) Details() string {
+	var details []string
+	if len(e.Card.Labels) > 0 {
+		details = append(details, "labels: "+strings.Join(domain.LabelNames(e.Card.Labels), ", "))
+	}
+	if len(e.Card.Members) > 0 {
+		details = append(details, "members: "+strings.Join(e.Card.Members, ", "))
+	}
+	if e.Card.Due != nil {
+		details = append(details, "due "+e.Card.Due.Time.Format(dateLayout))
+	}
+	if len(e.Unknown) > 0 {
+		details = append(details, "unknown labels: "+strings.Join(e.Unknown, ", "))
+	}
+	return strings.Join(details, " · ")
+}
+
+// Plan describes the cards created by importing a file into a list, so that they can be
+// previewed before running the import and displayed while it runs
+type Plan struct {
+	Path     string
+	BoardID  string
+	ListID   string
+	ListName string
+
+	entries []Entry
+	journal string // path of the file recording the cards created, for resuming the import
+	m       sync.RWMutex
+}
+
+// NewPlan reads the file at the provided path and returns the plan for importing its cards into
+// the list at index listIdx of the board, labels are the labels available on the board
+func NewPlan(path string, b *domain.Board, listIdx int, labels []domain.CardLabel) (*Plan, error) {
+	if listIdx < 0 || listIdx >= len(b.Lists) {
+		return nil, errors.New("list not found")
+	}
+	format := FormatOf(path)
+	if format == "" {
+		return nil, errors.Errorf("unknown import format for %s (available: %s, %s)", path, CSV, Markdown)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read %s", path)
+	}
+	cards, err := Read(data, format)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read %s", path)
+	}
+	if len(cards) == 0 {
+		return nil, errors.Errorf("no cards found in %s", path)
+	}
+
+	l := b.Lists[listIdx]
+	p := &Plan{
+		Path:     path,
+		BoardID:  b.ID,
+		ListID:   l.ID,
+		ListName: l.Name,
+		entries:  make([]Entry, len(cards)),
+		journal:  journalPath(data, b.ID, l.ID),
+	}
+	for i, c := range cards {
+		p.entries[i] = Entry{Card: c}
+		p.entries[i].Card.Labels, p.entries[i].Unknown = domain.MatchLabels(domain.LabelNames(c.Labels), labels)
+	}
+	if err := p.readJournal(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Entries returns a copy of the cards of the import, which may be read while Run updates them
+func (p *Plan) Entries() []Entry {
+	p.m.RLock()
+	defer p.m.RUnlock()
+	entries := make([]Entry, len(p.entries))
+	copy(entries, p.entries)
+	return entries
+}
+
+// Pending returns the number of cards which were not imported yet
+func (p *Plan) Pending() int {
+	p.m.RLock()
+	defer p.m.RUnlock()
+	var n int
+	for _, e := range p.entries {
+		if !e.Imported {
+			n++
+		}
+	}
+	return n
+}
+
+// Missing returns the number of labels and members left out of the cards created since they were
+// not found on the board
+func (p *Plan) Missing() int {
+	p.m.RLock()
+	defer p.m.RUnlock()
+	var n int
+	for _, e := range p.entries {
+		n += e.Missing
+	}
+	return n
+}
+
+// Run creates the cards not imported yet in order, calling created after each card, the cards
+// created are recorded in a journal so that running the plan again after an interruption, even
+// from another process, only creates the remaining ones, the journal is removed once done
+func (p *Plan) Run(create func(c domain.Card) (domain.Card, error), created func(c domain.Card)) error {
+	if err := os.MkdirAll(filepath.Dir(p.journal), 0700); err != nil {
+		return errors.Wrap(err, "could not create import journal")
+	}
+	f, err := os.OpenFile(p.journal, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrap(err, "could not open import journal")
+	}
+	defer f.Close()
+
+	for i, e := range p.Entries() {
+		if e.Imported {
+			continue
+		}
+		c, err := create(e.Card)
+		if err != nil {
+			return errors.Wrapf(err, "could not import card %q", e.Card.Name)
+		}
+		p.m.Lock()
+		p.entries[i].Imported = true
+		p.entries[i].Missing = len(e.Card.Labels) + len(e.Card.Members) - len(c.Labels) - len(c.Members)
+		p.m.Unlock()
+		if _, err := fmt.Fprintf(f, "%d\t%s\n", i, c.ID); err != nil {
+			return errors.Wrap(err, "could not write import journal")
+		}
+		created(c)
+	}
+	if err := f.Close(); err != nil {
+		return errors.Wrap(err, "could not write import journal")
+	}
+	return errors.Wrap(os.Remove(p.journal), "could not remove import journal")
+}
+
+// readJournal marks the entries recorded in the journal as imported
+func (p *Plan) readJournal() error {
+	f, err := os.Open(p.journal)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "could not open import journal")
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		// the last line may be incomplete if the import was interrupted while writing it
+		fields := strings.Split(s.Text(), "\t")
+		idx, err := strconv.Atoi(fields[0])
+		if err != nil || len(fields) != 2 || idx < 0 || idx >= len(p.entries) {
+			continue
+		}
+		p.entries[idx].Imported = true
+	}
+	return errors.Wrap(s.Err(), "could not read import journal")
+}
+
+// journalPath returns the path of the journal of the import of the file content into the list,
+// in the user cache directory
+func journalPath(data []byte, boardID, listID string) string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	h := sha1.New()
+	_, _ = h.Write(data)
+	_, _ = h.Write([]byte("\x00" + boardID + "\x00" + listID))
+	return filepath.Join(dir, "trello-tui", "imports", hex.EncodeToString(h.Sum(nil)))
+}
@@ -0,0 +1,193 @@
+package importer
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/giannimassi/trello-tui/pkg/domain"
+	"github.com/giannimassi/trello-tui/pkg/trello"
+	"github.com/giannimassi/trello-tui/pkg/trello/fake"
+)
+
+func TestMain(m *testing.M) {
+	zerolog.SetGlobalLevel(zerolog.Disabled)
+	// the journals of the imports are written to the user cache directory
+	dir, err := ioutil.TempDir("", "importer")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("XDG_CACHE_HOME", dir)
+	os.Setenv("HOME", dir)
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+const importCSV = "title,labels,members\n" +
+	"First,bug,Jane Doe\n" +
+	"Second,\"feature, unknown\",\n" +
+	"Third,,nobody\n"
+
+// demoImport returns a client of a fake server seeded with the demo board, the board and the
+// labels available on it, and the path of a CSV file with the cards to import
+func demoImport(t *testing.T) (*trello.Client, *fake.Server, *domain.Board, []domain.CardLabel, string) {
+	t.Helper()
+	server := fake.NewDemoServer()
+	client := trello.NewClient(&trello.Config{
+		User:    fake.DemoUser,
+		Key:     "key",
+		Token:   "token",
+		Timeout: 5 * time.Second,
+		BaseURL: server.APIURL(),
+	})
+	if err := client.Init(); err != nil {
+		server.Close()
+		t.Fatal(err)
+	}
+	b, err := client.Board(fake.DemoBoard)
+	if err != nil {
+		server.Close()
+		t.Fatal(err)
+	}
+	labels, err := client.Labels(b.ID)
+	if err != nil {
+		server.Close()
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "import")
+	if err != nil {
+		server.Close()
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "cards.csv")
+	if err := ioutil.WriteFile(path, []byte(importCSV), 0600); err != nil {
+		server.Close()
+		t.Fatal(err)
+	}
+	return client, server, b, labels, path
+}
+
+// backlogCards returns the names of the cards of the first list of the demo board
+func backlogCards(t *testing.T, client *trello.Client) []string {
+	t.Helper()
+	b, err := client.Board(fake.DemoBoard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, id := range b.Lists[0].CartIds {
+		names = append(names, b.Lists[0].CardsByID[id].Name)
+	}
+	return names
+}
+
+func TestNewPlan(t *testing.T) {
+	_, server, b, labels, path := demoImport(t)
+	defer server.Close()
+	defer os.RemoveAll(filepath.Dir(path))
+
+	plan, err := NewPlan(path, b, 0, labels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.BoardID != b.ID || plan.ListID != b.Lists[0].ID || plan.ListName != b.Lists[0].Name {
+		t.Errorf("plan = %+v", plan)
+	}
+	entries := plan.Entries()
+	if len(entries) != 3 || plan.Pending() != 3 {
+		t.Fatalf("entries = %+v, want 3 pending", entries)
+	}
+	if names := domain.LabelNames(entries[1].Card.Labels); len(names) != 1 || names[0] != "feature" {
+		t.Errorf("labels = %v, want [feature]", names)
+	}
+	if len(entries[1].Unknown) != 1 || entries[1].Unknown[0] != "unknown" {
+		t.Errorf("unknown labels = %v, want [unknown]", entries[1].Unknown)
+	}
+	if details := entries[1].Details(); details != "labels: feature · unknown labels: unknown" {
+		t.Errorf("details = %q", details)
+	}
+
+	if _, err := NewPlan(path, b, len(b.Lists), labels); err == nil {
+		t.Error("no error for a list out of range")
+	}
+	if _, err := NewPlan(filepath.Join(filepath.Dir(path), "cards.json"), b, 0, labels); err == nil {
+		t.Error("no error for an unknown format")
+	}
+}
+
+func TestPlanRunResume(t *testing.T) {
+	client, server, b, labels, path := demoImport(t)
+	defer server.Close()
+	defer os.RemoveAll(filepath.Dir(path))
+	before := backlogCards(t, client)
+
+	create := func(plan *Plan) func(c domain.Card) (domain.Card, error) {
+		return func(c domain.Card) (domain.Card, error) {
+			return client.CreateCard(plan.BoardID, plan.ListID, c)
+		}
+	}
+
+	// the creation of the second card fails, interrupting the import
+	plan, err := NewPlan(path, b, 0, labels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var created []string
+	err = plan.Run(create(plan), func(c domain.Card) {
+		created = append(created, c.Name)
+		server.FailNext(1, http.StatusBadRequest)
+	})
+	if err == nil {
+		t.Fatal("no error for the interrupted import")
+	}
+	if len(created) != 1 || plan.Pending() != 2 || !plan.Entries()[0].Imported {
+		t.Errorf("created = %v, pending = %d, want only the first card imported", created, plan.Pending())
+	}
+
+	// planning the import again, e.g. from another process, skips the card already imported
+	plan, err = NewPlan(path, b, 0, labels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries := plan.Entries(); !entries[0].Imported || entries[1].Imported || plan.Pending() != 2 {
+		t.Fatalf("entries = %+v, want the first card imported", entries)
+	}
+	created = nil
+	if err := plan.Run(create(plan), func(c domain.Card) { created = append(created, c.Name) }); err != nil {
+		t.Fatal(err)
+	}
+	if len(created) != 2 || created[0] != "Second" || created[1] != "Third" || plan.Pending() != 0 {
+		t.Errorf("created = %v, pending = %d, want the remaining cards", created, plan.Pending())
+	}
+	// the member of the third card is not on the board
+	if missing := plan.Missing(); missing != 1 {
+		t.Errorf("missing = %d, want 1", missing)
+	}
+
+	after := backlogCards(t, client)
+	want := append(before, "First", "Second", "Third")
+	if len(after) != len(want) {
+		t.Fatalf("cards = %v, want %v", after, want)
+	}
+	for i := range want {
+		if after[i] != want[i] {
+			t.Errorf("cards = %v, want %v", after, want)
+			break
+		}
+	}
+
+	// the journal is removed once the import is complete, so importing again creates all the cards
+	plan, err = NewPlan(path, b, 0, labels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Pending() != 3 {
+		t.Errorf("pending = %d after a complete import, want 3", plan.Pending())
+	}
+}
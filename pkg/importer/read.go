@@ -0,0 +1,131 @@
+// Package importer creates cards in a list from a CSV file or a Markdown checklist, matching
+// their labels with the ones of the board, and resumes imports which were interrupted
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/giannimassi/trello-tui/pkg/domain"
+	"github.com/giannimassi/trello-tui/pkg/markdown"
+)
+
+// Supported formats
+const (
+	CSV      = "csv"
+	Markdown = "md"
+)
+
+// FormatOf returns the format corresponding to the extension of the path, or an empty string if
+// the extension does not match a supported format
+func FormatOf(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return CSV
+	case ".md", ".markdown", ".txt":
+		return Markdown
+	}
+	return ""
+}
+
+// Read returns the cards of the data in the provided format:
+//   - CSV files have a header naming the columns, `title` (or `name`), `description`, `labels`,
+//     `members`, `due` and `due_complete`, other columns are ignored so that exports can be imported
+//   - Markdown checklists have an item per card, see markdown.Items
+func Read(data []byte, format string) ([]domain.Card, error) {
+	switch format {
+	case CSV:
+		return readCSV(bytes.NewReader(data))
+	case Markdown:
+		return markdown.Items(data), nil
+	}
+	return nil, errors.Errorf("unknown import format %q (available: %s, %s)", format, CSV, Markdown)
+}
+
+// readCSV returns the cards of the rows of the CSV file, multiple labels and members are
+// separated by commas
+func readCSV(r io.Reader) ([]domain.Card, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read header")
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, found := columns["title"]; !found {
+		if idx, found := columns["name"]; found {
+			columns["title"] = idx
+		} else {
+			return nil, errors.New("missing title column")
+		}
+	}
+
+	var cards []domain.Card
+	now := time.Now()
+	for row := 2; ; row++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return cards, nil
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read row %d", row)
+		}
+		field := func(column string) string {
+			idx, found := columns[column]
+			if !found || idx >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[idx])
+		}
+		title := field("title")
+		if title == "" {
+			continue
+		}
+
+		var labels []domain.CardLabel
+		for _, name := range Split(field("labels")) {
+			labels = append(labels, domain.CardLabel{Name: name})
+		}
+		var due *domain.Due
+		if value := field("due"); value != "" {
+			t, err := domain.ParseDue(value, now)
+			if err != nil {
+				return nil, errors.Errorf("row %d: invalid due date %q", row, value)
+			}
+			due = &domain.Due{Time: t}
+			if value := field("due_complete"); value != "" {
+				if due.Complete, err = strconv.ParseBool(value); err != nil {
+					return nil, errors.Errorf("row %d: invalid due_complete %q", row, value)
+				}
+			}
+		}
+		c := domain.NewCard("", 0, title, field("description"), 0, labels, due)
+		c.Members = Split(field("members"))
+		cards = append(cards, c)
+	}
+}
+
+// Split returns the values of the comma separated list, ignoring empty ones
+func Split(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
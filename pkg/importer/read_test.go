@@ -0,0 +1,125 @@
+package importer
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/giannimassi/trello-tui/pkg/domain"
+)
+
+func TestFormatOf(t *testing.T) {
+	tests := map[string]string{
+		"cards.csv":    CSV,
+		"CARDS.CSV":    CSV,
+		"todo.md":      Markdown,
+		"todo.txt":     Markdown,
+		"notes.json":   "",
+		"no-extension": "",
+	}
+	for path, want := range tests {
+		if got := FormatOf(path); got != want {
+			t.Errorf("FormatOf(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	data := "Title, Description, Labels, Members, Due, Due_Complete, Extra\n" +
+		"Write docs, For the release, \"docs, chore\", Jane Doe, 2030-03-01 09:30, true, ignored\n" +
+		"\n" +
+		", skipped without a title,,,,,\n" +
+		"Short row\n"
+	cards, err := Read([]byte(data), CSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cards) != 2 {
+		t.Fatalf("cards = %+v, want 2", cards)
+	}
+	c := cards[0]
+	if c.Name != "Write docs" || c.Description != "For the release" {
+		t.Errorf("card = %+v", c)
+	}
+	if names := domain.LabelNames(c.Labels); !reflect.DeepEqual(names, []string{"docs", "chore"}) {
+		t.Errorf("labels = %v", names)
+	}
+	if !reflect.DeepEqual(c.Members, []string{"Jane Doe"}) {
+		t.Errorf("members = %v", c.Members)
+	}
+	want := time.Date(2030, time.March, 1, 9, 30, 0, 0, time.Local)
+	if c.Due == nil || !c.Due.Time.Equal(want) || !c.Due.Complete {
+		t.Errorf("due = %+v, want %v complete", c.Due, want)
+	}
+	if cards[1].Name != "Short row" || cards[1].Due != nil || len(cards[1].Labels) != 0 {
+		t.Errorf("short row = %+v", cards[1])
+	}
+}
+
+func TestReadCSVErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "missing title column", data: "description\nsomething\n"},
+		{name: "invalid due date", data: "title,due\nCard,someday\n"},
+		{name: "invalid due_complete", data: "title,due,due_complete\nCard,2030-01-01,maybe\n"},
+		{name: "unterminated quote", data: "title\n\"Card\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Read([]byte(tt.data), CSV); err == nil {
+				t.Errorf("no error for %q", tt.data)
+			}
+		})
+	}
+}
+
+func TestReadCSVNameColumn(t *testing.T) {
+	cards, err := Read([]byte("name\nFirst\nSecond\n"), CSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cards) != 2 || cards[0].Name != "First" || cards[1].Name != "Second" {
+		t.Errorf("cards = %+v", cards)
+	}
+}
+
+func TestReadMarkdown(t *testing.T) {
+	data := "# Sprint\n\n" +
+		"- [ ] Fix login #bug #urgent\n" +
+		"\tHappens when the session expires\n" +
+		"- [x] Release @{2030-03-01}\n" +
+		"Not an item\n"
+	cards, err := Read([]byte(data), Markdown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cards) != 2 {
+		t.Fatalf("cards = %+v, want 2", cards)
+	}
+	if cards[0].Name != "Fix login" || cards[0].Description != "Happens when the session expires" {
+		t.Errorf("first card = %+v", cards[0])
+	}
+	if names := domain.LabelNames(cards[0].Labels); !reflect.DeepEqual(names, []string{"bug", "urgent"}) {
+		t.Errorf("labels = %v", names)
+	}
+	if cards[1].Name != "Release" || cards[1].Due == nil {
+		t.Errorf("second card = %+v", cards[1])
+	}
+}
+
+func TestReadUnknownFormat(t *testing.T) {
+	if _, err := Read([]byte("{}"), "json"); err == nil {
+		t.Error("no error for an unknown format")
+	}
+}
+
+func TestSplit(t *testing.T) {
+	if got := Split(" a, ,b ,, c "); !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+		t.Errorf("Split() = %v", got)
+	}
+	if got := Split(""); got != nil {
+		t.Errorf("Split(\"\") = %v, want nil", got)
+	}
+}
@@ -0,0 +1,478 @@
+// Package markdown stores boards in Markdown files in the format of the Obsidian Kanban plugin,
+// where `## List` headings are lists and `- [ ] card` items are cards
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/giannimassi/trello-tui/pkg/domain"
+)
+
+const (
+	// dateLayout and timeLayout are the layouts of the `@{date}` and `@@{time}` tokens
+	dateLayout = "2006-01-02"
+	timeLayout = "15:04"
+
+	// archiveSeparator separates the lists from the archived cards, as done by Obsidian Kanban
+	archiveSeparator = "***"
+	// archiveHeading is the heading of the archived cards
+	archiveHeading = "## Archive"
+	// settingsPrefix starts the block of the Obsidian Kanban settings
+	settingsPrefix = "%% kanban:settings"
+)
+
+var (
+	titleRe    = regexp.MustCompile(`^#\s+(.*)$`)
+	headingRe  = regexp.MustCompile(`^(##\s+)(.*)$`)
+	itemRe     = regexp.MustCompile(`^([-*+] )(\[([ xX])\] )?(.*)$`)
+	tagRe      = regexp.MustCompile(`(^|\s)#([^\s#]+)`)
+	timeRe     = regexp.MustCompile(`\s*@@\{([^}]*)\}`)
+	dateRe     = regexp.MustCompile(`\s*@\{([^}]*)\}`)
+	spaceRe    = regexp.MustCompile(`\s+`)
+	checkboxRe = regexp.MustCompile(`^[-*+] \[[ xX]\] `)
+)
+
+// document is a Markdown board file split in the parts which are modified independently, so that
+// the rest of the file is written back as it was read
+type document struct {
+	crlf  bool
+	head  []string   // lines before the first list, e.g. front matter and title
+	lists []*section // one per `##` heading
+	tail  []string   // archived cards and settings
+}
+
+// section is a list of the board, starting with its heading
+type section struct {
+	heading string
+	blocks  []*block
+
+	id  string
+	pos float64
+}
+
+// block is either a card, made of its item line followed by indented lines, or any other line
+type block struct {
+	lines []string
+	item  bool
+
+	id     string
+	number int
+	pos    float64
+}
+
+// parse splits the content of a Markdown board file
+func parse(data []byte) *document {
+	text := string(data)
+	d := &document{crlf: strings.Contains(text, "\r\n")}
+	text = strings.Replace(text, "\r\n", "\n", -1)
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	if text == "" {
+		lines = nil
+	}
+
+	var (
+		current  *section
+		lastItem *block
+		inFront  bool
+	)
+	for i, line := range lines {
+		switch {
+		case d.tail != nil:
+			d.tail = append(d.tail, line)
+		case i == 0 && line == "---":
+			inFront = true
+			d.head = append(d.head, line)
+		case inFront:
+			inFront = line != "---"
+			d.head = append(d.head, line)
+		case line == archiveSeparator || strings.HasPrefix(line, settingsPrefix):
+			d.tail = append([]string{}, line)
+		case headingRe.MatchString(line):
+			current = &section{heading: line}
+			lastItem = nil
+			d.lists = append(d.lists, current)
+		case current == nil:
+			d.head = append(d.head, line)
+		case itemRe.MatchString(line):
+			lastItem = &block{lines: []string{line}, item: true}
+			current.blocks = append(current.blocks, lastItem)
+		case lastItem != nil && strings.TrimSpace(line) != "" && (line[0] == ' ' || line[0] == '\t'):
+			lastItem.lines = append(lastItem.lines, line)
+		default:
+			lastItem = nil
+			current.blocks = append(current.blocks, &block{lines: []string{line}})
+		}
+	}
+	d.index()
+	return d
+}
+
+// bytes returns the content of the file
+func (d *document) bytes() []byte {
+	var lines []string
+	lines = append(lines, d.head...)
+	for _, s := range d.lists {
+		lines = append(lines, s.heading)
+		for _, b := range s.blocks {
+			lines = append(lines, b.lines...)
+		}
+	}
+	lines = append(lines, d.tail...)
+	newline := "\n"
+	if d.crlf {
+		newline = "\r\n"
+	}
+	var buf bytes.Buffer
+	for _, line := range lines {
+		buf.WriteString(line)
+		buf.WriteString(newline)
+	}
+	return buf.Bytes()
+}
+
+// index assigns the ids, numbers and positions of the lists and cards, ids are derived from the
+// names so that they do not change when lists and cards are moved. Cards sharing the same name are
+// told apart by their description, only identical cards get ids depending on their order, which
+// may swap when they are reordered
+func (d *document) index() {
+	cards := make(map[string]int)
+	for _, s := range d.lists {
+		for _, b := range s.blocks {
+			if b.item {
+				cards[parseCard(b.lines).name]++
+			}
+		}
+	}
+
+	listIDs := make(map[string]bool)
+	cardIDs := make(map[string]bool)
+	var number int
+	for i, s := range d.lists {
+		s.id = uniqueID("list-", s.name(), listIDs)
+		s.pos = float64(i+1) * domain.PosStep
+		var j int
+		for _, b := range s.blocks {
+			if !b.item {
+				continue
+			}
+			number++
+			j++
+			c := parseCard(b.lines)
+			key := c.name
+			if cards[c.name] > 1 {
+				key += "\x00" + c.description
+			}
+			b.id = uniqueID("card-", key, cardIDs)
+			b.number = number
+			b.pos = float64(j) * domain.PosStep
+		}
+	}
+}
+
+// uniqueID returns an id derived from the name, not included in ids, and adds it to ids
+func uniqueID(prefix, name string, ids map[string]bool) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name))
+	base := fmt.Sprintf("%s%08x", prefix, h.Sum32())
+	id := base
+	for n := 2; ids[id]; n++ {
+		id = fmt.Sprintf("%s-%d", base, n)
+	}
+	ids[id] = true
+	return id
+}
+
+// name returns the title of the board, from the `#` heading before the lists if any
+func (d *document) name(path string) string {
+	for _, line := range d.head {
+		if m := titleRe.FindStringSubmatch(line); m != nil {
+			return strings.TrimSpace(m[1])
+		}
+	}
+	return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+}
+
+// list returns the index of the list with the corresponding id, -1 if not found
+func (d *document) list(id string) int {
+	for i, s := range d.lists {
+		if s.id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// card returns the list containing the card with the corresponding id and the index of its block
+func (d *document) card(id string) (*section, int) {
+	for _, s := range d.lists {
+		for i, b := range s.blocks {
+			if b.item && b.id == id {
+				return s, i
+			}
+		}
+	}
+	return nil, -1
+}
+
+// domain returns the board as a domain.Board, path is used as the id of the board
+func (d *document) domain(path string) *domain.Board {
+	lists := make([]domain.List, len(d.lists))
+	isEmpty := true
+	for i, s := range d.lists {
+		cards := make(map[string]domain.Card)
+		for _, b := range s.blocks {
+			if !b.item {
+				continue
+			}
+			c := parseCard(b.lines)
+			labels := make([]domain.CardLabel, len(c.tags))
+			for j, tag := range c.tags {
+				labels[j] = domain.CardLabel{Name: tag}
+			}
+			cards[b.id] = domain.NewCard(b.id, b.number, c.name, c.description, b.pos, labels, c.due)
+			isEmpty = false
+		}
+		lists[i] = domain.NewList(s.id, s.name(), s.pos, cards)
+	}
+	return domain.NewBoard(path, d.name(path), "", lists, isEmpty)
+}
+
+// name returns the name of the list
+func (s *section) name() string {
+	return strings.TrimSpace(headingRe.FindStringSubmatch(s.heading)[2])
+}
+
+// rename replaces the name of the list in its heading
+func (s *section) rename(name string) {
+	s.heading = headingRe.FindStringSubmatch(s.heading)[1] + name
+}
+
+// insertCard inserts the card block before the card at index idx, or after the last card
+func (s *section) insertCard(idx int, b *block) {
+	at := len(s.blocks)
+	var cards int
+	for i, other := range s.blocks {
+		if !other.item {
+			continue
+		}
+		if cards == idx {
+			at = i
+			break
+		}
+		cards++
+		at = i + 1
+	}
+	s.blocks = append(s.blocks, nil)
+	copy(s.blocks[at+1:], s.blocks[at:])
+	s.blocks[at] = b
+}
+
+// labels returns the tags of the cards of the lists, sorted by name
+func (d *document) labels() []domain.CardLabel {
+	var labels []domain.CardLabel
+	found := make(map[string]bool)
+	for _, s := range d.lists {
+		for _, b := range s.blocks {
+			if !b.item {
+				continue
+			}
+			for _, tag := range parseCard(b.lines).tags {
+				if !found[tag] {
+					found[tag] = true
+					labels = append(labels, domain.CardLabel{Name: tag})
+				}
+			}
+		}
+	}
+	sort.Slice(labels, func(i, j int) bool { return labels[i].Name < labels[j].Name })
+	return labels
+}
+
+// newBlock returns the card block of the card, made of its item line followed by its description
+// indented with a tab
+func newBlock(c domain.Card) *block {
+	line := "- [ ] " + strings.Join(strings.Fields(c.Name), " ")
+	for _, l := range c.Labels {
+		line += " #" + strings.Join(strings.Fields(l.Name), "-")
+	}
+	if c.Due != nil {
+		line = setChecked(setDue(line, &c.Due.Time), c.Due.Complete)
+	}
+	b := &block{lines: []string{line}, item: true}
+	if c.Description != "" {
+		for _, description := range strings.Split(c.Description, "\n") {
+			b.lines = append(b.lines, "\t"+description)
+		}
+	}
+	return b
+}
+
+// Items returns the cards of the items of the Markdown text, e.g. a checklist, regardless of the
+// lists they belong to, with their indented lines as description, `#tags` as labels and
+// `@{date}` and `@@{time}` as due date
+func Items(data []byte) []domain.Card {
+	var (
+		cards []domain.Card
+		lines []string
+	)
+	flush := func() {
+		if lines == nil {
+			return
+		}
+		c := parseCard(lines)
+		labels := make([]domain.CardLabel, len(c.tags))
+		for i, tag := range c.tags {
+			labels[i] = domain.CardLabel{Name: tag}
+		}
+		cards = append(cards, domain.NewCard("", 0, c.name, c.description, 0, labels, c.due))
+		lines = nil
+	}
+	for _, line := range strings.Split(strings.Replace(string(data), "\r\n", "\n", -1), "\n") {
+		switch {
+		case line == archiveSeparator || strings.HasPrefix(line, settingsPrefix):
+			// archived cards and settings of Obsidian Kanban boards are not items
+			flush()
+			return cards
+		case itemRe.MatchString(line):
+			flush()
+			lines = []string{line}
+		case lines != nil && strings.TrimSpace(line) != "" && (line[0] == ' ' || line[0] == '\t'):
+			lines = append(lines, line)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return cards
+}
+
+// card is the content of a card item
+type card struct {
+	name        string
+	description string
+	tags        []string
+	due         *domain.Due
+}
+
+// parseCard returns the content of the card item made of the lines provided
+func parseCard(lines []string) card {
+	m := itemRe.FindStringSubmatch(lines[0])
+	checked := m[3] == "x" || m[3] == "X"
+	text := m[4]
+
+	var c card
+	// time tokens are removed first since `@@{time}` contains `@{time}`
+	var date, clock string
+	if tm := timeRe.FindStringSubmatch(text); tm != nil {
+		clock = tm[1]
+	}
+	text = timeRe.ReplaceAllString(text, "")
+	if dm := dateRe.FindStringSubmatch(text); dm != nil {
+		date = dm[1]
+	}
+	text = dateRe.ReplaceAllString(text, "")
+	for _, tm := range tagRe.FindAllStringSubmatch(text, -1) {
+		c.tags = append(c.tags, tm[2])
+	}
+	text = tagRe.ReplaceAllString(text, "$1")
+	c.name = strings.TrimSpace(spaceRe.ReplaceAllString(text, " "))
+
+	var description []string
+	for _, line := range lines[1:] {
+		description = append(description, strings.TrimLeft(line, " \t"))
+	}
+	c.description = strings.Join(description, "\n")
+	c.due = parseDue(date, clock, checked)
+	return c
+}
+
+// parseDue returns the due date of the `@{date}` and `@@{time}` tokens, nil if there is no date
+func parseDue(date, clock string, complete bool) *domain.Due {
+	if date == "" {
+		return nil
+	}
+	t, err := time.ParseInLocation(dateLayout, date, time.Local)
+	if err != nil {
+		return nil
+	}
+	if c, err := time.Parse(timeLayout, clock); err == nil {
+		t = t.Add(time.Duration(c.Hour())*time.Hour + time.Duration(c.Minute())*time.Minute)
+	}
+	return &domain.Due{Time: t, Complete: complete}
+}
+
+// setDue replaces the date and time tokens of the card item line, a nil due removes them
+func setDue(line string, due *time.Time) string {
+	line = timeRe.ReplaceAllString(line, "")
+	line = dateRe.ReplaceAllString(line, "")
+	if due == nil {
+		return line
+	}
+	local := due.Local()
+	line += " @{" + local.Format(dateLayout) + "}"
+	if local.Hour() != 0 || local.Minute() != 0 {
+		line += " @@{" + local.Format(timeLayout) + "}"
+	}
+	return line
+}
+
+// setChecked marks the card item line as checked or not, adding the checkbox if missing
+func setChecked(line string, checked bool) string {
+	box := "[ ] "
+	if checked {
+		box = "[x] "
+	}
+	if checkboxRe.MatchString(line) {
+		return line[:2] + box + line[len("- [ ] "):]
+	}
+	return line[:2] + box + line[2:]
+}
+
+// archive adds the lines of the archived cards to the archive, which is created if missing
+func (d *document) archive(lines []string) {
+	if len(lines) == 0 {
+		return
+	}
+	at := -1
+	for i, line := range d.tail {
+		if line == archiveHeading {
+			at = i + 1
+			break
+		}
+	}
+	switch {
+	case at < 0:
+		d.tail = append([]string{archiveSeparator, "", archiveHeading, "", ""}, d.tail...)
+		at = 4
+	case at < len(d.tail) && d.tail[at] == "":
+		// the cards are added after the empty line following the heading
+		at++
+	}
+	tail := make([]string, 0, len(d.tail)+len(lines))
+	tail = append(tail, d.tail[:at]...)
+	tail = append(tail, lines...)
+	d.tail = append(tail, d.tail[at:]...)
+}
+
+// separate ensures that each list is followed by an empty line, as lists may have been moved
+// after the last one, which may not end with one
+func (d *document) separate() {
+	if len(d.lists) > 0 && len(d.head) > 0 && strings.TrimSpace(d.head[len(d.head)-1]) != "" {
+		d.head = append(d.head, "")
+	}
+	for i, s := range d.lists {
+		if i == len(d.lists)-1 && len(d.tail) == 0 {
+			continue
+		}
+		if n := len(s.blocks); n == 0 || s.blocks[n-1].item || strings.TrimSpace(s.blocks[n-1].lines[0]) != "" {
+			s.blocks = append(s.blocks, &block{lines: []string{""}})
+		}
+	}
+}
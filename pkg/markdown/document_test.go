@@ -0,0 +1,308 @@
+package markdown
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/giannimassi/trello-tui/pkg/domain"
+)
+
+func TestMain(m *testing.M) {
+	// due dates are written in the local time zone
+	time.Local = time.UTC
+	zerolog.SetGlobalLevel(zerolog.Disabled)
+	os.Exit(m.Run())
+}
+
+// readTestdata returns the content of the file of the testdata directory
+func readTestdata(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := ioutil.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestParseRoundTrip(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "*.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no testdata")
+	}
+	for _, path := range paths {
+		data := readTestdata(t, filepath.Base(path))
+		crlf := []byte(strings.Replace(string(data), "\n", "\r\n", -1))
+		for name, data := range map[string][]byte{filepath.Base(path): data, filepath.Base(path) + " crlf": crlf} {
+			t.Run(name, func(t *testing.T) {
+				if got := parse(data).bytes(); string(got) != string(data) {
+					t.Errorf("bytes() =\n%q\nwant\n%q", got, data)
+				}
+			})
+		}
+	}
+}
+
+func TestParseMissingNewline(t *testing.T) {
+	if got := string(parse([]byte("## To Do\n- [ ] Card")).bytes()); got != "## To Do\n- [ ] Card\n" {
+		t.Errorf("bytes() = %q", got)
+	}
+	if got := parse(nil).bytes(); len(got) != 0 {
+		t.Errorf("bytes() = %q for an empty file", got)
+	}
+}
+
+func TestParse(t *testing.T) {
+	d := parse(readTestdata(t, "kanban.md"))
+	if len(d.head) != 8 || d.name("board.md") != "Release board" {
+		t.Errorf("head = %q", d.head)
+	}
+	if len(d.tail) == 0 || d.tail[0] != archiveSeparator {
+		t.Errorf("tail = %q", d.tail)
+	}
+
+	b := d.domain("board.md")
+	var names []string
+	for _, l := range b.Lists {
+		names = append(names, l.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"Backlog", "In Progress", "Done"}) {
+		t.Fatalf("lists = %v", names)
+	}
+	if len(b.Lists[0].CartIds) != 3 || len(b.Lists[1].CartIds) != 2 || len(b.Lists[2].CartIds) != 0 {
+		t.Errorf("cards = %v", b.Lists)
+	}
+
+	c := b.Lists[0].CardsByID[b.Lists[0].CartIds[1]]
+	if c.Name != "Fix login" || c.IdShort != 2 || c.Description != "Happens when the session expires\nand the page is reloaded" {
+		t.Errorf("card = %+v", c)
+	}
+	if names := domain.LabelNames(c.Labels); !reflect.DeepEqual(names, []string{"bug", "urgent"}) {
+		t.Errorf("labels = %v", names)
+	}
+	if want := time.Date(2030, time.January, 18, 17, 0, 0, 0, time.UTC); c.Due == nil || !c.Due.Time.Equal(want) || c.Due.Complete {
+		t.Errorf("due = %+v, want %v", c.Due, want)
+	}
+	done := b.Lists[0].CardsByID[b.Lists[0].CartIds[2]]
+	if want := time.Date(2030, time.January, 10, 0, 0, 0, 0, time.UTC); done.Due == nil || !done.Due.Time.Equal(want) || !done.Due.Complete {
+		t.Errorf("due = %+v, want %v complete", done.Due, want)
+	}
+	plain := b.Lists[1].CardsByID[b.Lists[1].CartIds[1]]
+	if plain.Name != "Plain item without a checkbox" || plain.IdShort != 5 {
+		t.Errorf("card = %+v", plain)
+	}
+}
+
+func TestIndexDuplicateNames(t *testing.T) {
+	d := parse(readTestdata(t, "plain.md"))
+	first, second := d.lists[0].blocks[0], d.lists[0].blocks[1]
+	if first.id == second.id {
+		t.Fatalf("cards with the same name have the same id %s", first.id)
+	}
+
+	// the cards with the same name keep their ids when reordered, since their descriptions differ
+	d.lists[0].blocks[0], d.lists[0].blocks[1] = second, first
+	firstID, secondID := first.id, second.id
+	d.index()
+	if first.id != firstID || second.id != secondID {
+		t.Errorf("ids = %s, %s after reordering, want %s, %s", first.id, second.id, firstID, secondID)
+	}
+
+	// identical cards are told apart by their order
+	d = parse([]byte("## To Do\n- [ ] Same\n- [ ] Same\n"))
+	if a, b := d.lists[0].blocks[0].id, d.lists[0].blocks[1].id; a == b || b != a+"-2" {
+		t.Errorf("ids = %s, %s", a, b)
+	}
+}
+
+func TestSetDue(t *testing.T) {
+	due := time.Date(2030, time.March, 1, 9, 5, 0, 0, time.UTC)
+	midnight := time.Date(2030, time.March, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		line string
+		due  *time.Time
+		want string
+	}{
+		{name: "add", line: "- [ ] Card #tag", due: &due, want: "- [ ] Card #tag @{2030-03-01} @@{09:05}"},
+		{name: "replace", line: "- [ ] Card @{2029-01-01} @@{10:00} #tag", due: &due, want: "- [ ] Card #tag @{2030-03-01} @@{09:05}"},
+		{name: "midnight has no time", line: "- [ ] Card @@{10:00}", due: &midnight, want: "- [ ] Card @{2030-03-01}"},
+		{name: "remove", line: "- [x] Card @{2029-01-01} @@{10:00} #tag", due: nil, want: "- [x] Card #tag"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := setDue(tt.line, tt.due); got != tt.want {
+				t.Errorf("setDue() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetChecked(t *testing.T) {
+	tests := []struct {
+		line    string
+		checked bool
+		want    string
+	}{
+		{line: "- [ ] Card", checked: true, want: "- [x] Card"},
+		{line: "* [X] Card", checked: false, want: "* [ ] Card"},
+		{line: "+ Card", checked: true, want: "+ [x] Card"},
+		{line: "- Card", checked: false, want: "- [ ] Card"},
+	}
+	for _, tt := range tests {
+		if got := setChecked(tt.line, tt.checked); got != tt.want {
+			t.Errorf("setChecked(%q, %v) = %q, want %q", tt.line, tt.checked, got, tt.want)
+		}
+	}
+}
+
+func TestNewBlock(t *testing.T) {
+	due := domain.Due{Time: time.Date(2030, time.March, 1, 9, 5, 0, 0, time.UTC), Complete: true}
+	c := domain.NewCard("", 0, "Write  the\tchangelog", "First line\nSecond line", 0,
+		[]domain.CardLabel{{Name: "docs"}, {Name: "good first issue"}}, &due)
+	want := []string{
+		"- [x] Write the changelog #docs #good-first-issue @{2030-03-01} @@{09:05}",
+		"\tFirst line",
+		"\tSecond line",
+	}
+	b := newBlock(c)
+	if !b.item || !reflect.DeepEqual(b.lines, want) {
+		t.Errorf("lines = %q, want %q", b.lines, want)
+	}
+	parsed := parseCard(b.lines)
+	if parsed.name != "Write the changelog" || parsed.description != c.Description || parsed.due == nil || !parsed.due.Time.Equal(due.Time) {
+		t.Errorf("parsed card = %+v", parsed)
+	}
+}
+
+func TestInsertCard(t *testing.T) {
+	d := parse([]byte("## To Do\n- [ ] First\nA note\n- [ ] Second\n\n"))
+	s := d.lists[0]
+	s.insertCard(1, newBlock(domain.Card{Name: "Inserted"}))
+	s.insertCard(-1, newBlock(domain.Card{Name: "Last"}))
+	s.insertCard(0, newBlock(domain.Card{Name: "Top"}))
+	want := "## To Do\n- [ ] Top\n- [ ] First\nA note\n- [ ] Inserted\n- [ ] Second\n- [ ] Last\n\n"
+	if got := string(d.bytes()); got != want {
+		t.Errorf("bytes() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRename(t *testing.T) {
+	d := parse([]byte("##   To Do\n- [ ] Card\n"))
+	d.lists[0].rename("Next")
+	if got := string(d.bytes()); got != "##   Next\n- [ ] Card\n" {
+		t.Errorf("bytes() = %q", got)
+	}
+	if name := d.lists[0].name(); name != "Next" {
+		t.Errorf("name() = %q", name)
+	}
+}
+
+func TestArchive(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			name: "new archive",
+			data: "## To Do\n- [ ] Card\n",
+			want: "## To Do\n- [ ] Card\n***\n\n## Archive\n\n- [x] Archived\n\n",
+		},
+		{
+			name: "new archive before the settings",
+			data: "## To Do\n- [ ] Card\n\n%% kanban:settings\n%%\n",
+			want: "## To Do\n- [ ] Card\n\n***\n\n## Archive\n\n- [x] Archived\n\n%% kanban:settings\n%%\n",
+		},
+		{
+			name: "existing archive",
+			data: "## To Do\n\n***\n\n## Archive\n\n- [x] Old\n",
+			want: "## To Do\n\n***\n\n## Archive\n\n- [x] Archived\n- [x] Old\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := parse([]byte(tt.data))
+			d.archive(nil)
+			d.archive([]string{"- [x] Archived"})
+			if got := string(d.bytes()); got != tt.want {
+				t.Errorf("bytes() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeparate(t *testing.T) {
+	d := parse([]byte("# Board\n## A\n- [ ] First\n## B\n- [ ] Second\n"))
+	// the last list is moved first, it does not end with an empty line
+	d.lists[0], d.lists[1] = d.lists[1], d.lists[0]
+	d.separate()
+	want := "# Board\n\n## B\n- [ ] Second\n\n## A\n- [ ] First\n"
+	if got := string(d.bytes()); got != want {
+		t.Errorf("bytes() =\n%q\nwant\n%q", got, want)
+	}
+	// separating again does not add more empty lines
+	d.separate()
+	if got := string(d.bytes()); got != want {
+		t.Errorf("bytes() after separating twice =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestSourceUpdates(t *testing.T) {
+	dir, err := ioutil.TempDir("", "markdown")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "board.md")
+	if err := ioutil.WriteFile(path, readTestdata(t, "kanban.md"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewSource(path)
+	if err := s.Init(); err != nil {
+		t.Fatal(err)
+	}
+	b, err := s.Board("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	backlog, progress, done := b.Lists[0], b.Lists[1], b.Lists[2]
+	login := backlog.CartIds[1]
+	if err := s.MoveCard(login, done.ID, domain.PosStep); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetCardDueComplete(login, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RenameList(progress.ID, "Doing"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ArchiveList(b.Lists[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CreateCard("", done.ID, domain.Card{Name: "Tag the release"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.MoveCard("unknown", done.ID, 0); err != ErrCardNotFound {
+		t.Errorf("MoveCard() = %v for an unknown card, want ErrCardNotFound", err)
+	}
+
+	got, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := readTestdata(t, "kanban-updated.md")
+	if string(got) != string(want) {
+		t.Errorf("file =\n%s\nwant\n%s", got, want)
+	}
+}
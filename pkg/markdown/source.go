@@ -0,0 +1,319 @@
+package markdown
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/giannimassi/trello-tui/pkg/domain"
+)
+
+// watchInterval is the interval at which the file is checked for changes
+const watchInterval = time.Second
+
+var (
+	// ErrBoardNotFound is returned when a board is not found
+	ErrBoardNotFound = errors.New("board not found")
+	// ErrListNotFound is returned when a change is requested on a list which is not part of the board
+	ErrListNotFound = errors.New("list not found")
+	// ErrCardNotFound is returned when a change is requested on a card which is not part of the board
+	ErrCardNotFound = errors.New("card not found")
+)
+
+// Source loads the board of a Markdown file and writes the changes back to the file, leaving the
+// rest of its content untouched
+type Source struct {
+	l    zerolog.Logger
+	path string
+	m    sync.Mutex
+}
+
+// NewSource returns a new instance of Source for the Markdown file at the provided path
+func NewSource(path string) *Source {
+	return &Source{
+		l:    log.With().Str("m", "markdown").Str("path", path).Logger(),
+		path: path,
+	}
+}
+
+// IsMarkdownFile returns true if the path has the extension of a Markdown file
+func IsMarkdownFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".md" || ext == ".markdown"
+}
+
+// Init checks that the file exists
+func (s *Source) Init() error {
+	if _, err := os.Stat(s.path); err != nil {
+		return errors.Wrapf(err, "could not open %s", s.path)
+	}
+	s.l.Info().Msg("Initialized")
+	return nil
+}
+
+// Board returns the board of the file if it has the provided name or the name is empty
+func (s *Source) Board(name string) (*domain.Board, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	d, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	if name != "" && !strings.EqualFold(d.name(s.path), name) {
+		return nil, ErrBoardNotFound
+	}
+	return d.domain(s.path), nil
+}
+
+// Boards returns the name of the board of the file
+func (s *Source) Boards() ([]string, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	d, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	return []string{d.name(s.path)}, nil
+}
+
+// Labels returns the tags used by the cards of the file
+func (s *Source) Labels(string) ([]domain.CardLabel, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	d, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	return d.labels(), nil
+}
+
+// Watch calls changed whenever the file is modified, e.g. by another editor, until the context
+// is done
+func (s *Source) Watch(ctx context.Context, changed func()) {
+	t := time.NewTicker(watchInterval)
+	defer t.Stop()
+	last := s.modTime()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if mod := s.modTime(); !mod.Equal(last) {
+				last = mod
+				s.l.Debug().Msg("File changed")
+				changed()
+			}
+		}
+	}
+}
+
+// modTime returns the modification time of the file, zero if it cannot be read
+func (s *Source) modTime() time.Time {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// CreateList creates a new list with the provided name and position
+func (s *Source) CreateList(_, name string, pos float64) (domain.List, error) {
+	s.l.Debug().Str("name", name).Msg("Creating list")
+	list := &section{heading: "## " + name, blocks: []*block{{lines: []string{""}}}}
+	err := s.update(func(d *document) error {
+		idx := len(d.lists)
+		for i, l := range d.lists {
+			if pos < l.pos {
+				idx = i
+				break
+			}
+		}
+		d.lists = append(d.lists, nil)
+		copy(d.lists[idx+1:], d.lists[idx:])
+		d.lists[idx] = list
+		return nil
+	})
+	if err != nil {
+		return domain.List{}, err
+	}
+	return domain.NewList(list.id, name, pos, map[string]domain.Card{}), nil
+}
+
+// RenameList sets the name of the list with the corresponding id
+func (s *Source) RenameList(listID, name string) error {
+	s.l.Debug().Str("list", listID).Str("name", name).Msg("Renaming list")
+	return s.updateList(listID, func(d *document, idx int) {
+		d.lists[idx].rename(name)
+	})
+}
+
+// ArchiveList removes the list with the corresponding id, its cards are moved to the archive
+func (s *Source) ArchiveList(listID string) error {
+	s.l.Debug().Str("list", listID).Msg("Archiving list")
+	return s.updateList(listID, func(d *document, idx int) {
+		var archived []string
+		for _, b := range d.lists[idx].blocks {
+			if b.item {
+				archived = append(archived, b.lines...)
+			}
+		}
+		d.lists = append(d.lists[:idx], d.lists[idx+1:]...)
+		d.archive(archived)
+	})
+}
+
+// MoveList sets the position of the list with the corresponding id
+func (s *Source) MoveList(listID string, pos float64) error {
+	s.l.Debug().Str("list", listID).Float64("pos", pos).Msg("Moving list")
+	return s.updateList(listID, func(d *document, idx int) {
+		l := d.lists[idx]
+		d.lists = append(d.lists[:idx], d.lists[idx+1:]...)
+		to := len(d.lists)
+		for i, other := range d.lists {
+			if pos < other.pos {
+				to = i
+				break
+			}
+		}
+		d.lists = append(d.lists, nil)
+		copy(d.lists[to+1:], d.lists[to:])
+		d.lists[to] = l
+	})
+}
+
+// SetCardDue sets the due date of the card with the corresponding id, a nil due removes the due date
+func (s *Source) SetCardDue(cardID string, due *time.Time) error {
+	s.l.Debug().Str("card", cardID).Msg("Setting card due date")
+	return s.updateCard(cardID, func(b *block) {
+		b.lines[0] = setDue(b.lines[0], due)
+	})
+}
+
+// SetCardDueComplete marks the card with the corresponding id as checked or not
+func (s *Source) SetCardDueComplete(cardID string, complete bool) error {
+	s.l.Debug().Str("card", cardID).Bool("complete", complete).Msg("Setting card due date completion")
+	return s.updateCard(cardID, func(b *block) {
+		b.lines[0] = setChecked(b.lines[0], complete)
+	})
+}
+
+// MoveCard moves the card with the corresponding id to the list with the corresponding id, at the provided position
+func (s *Source) MoveCard(cardID, listID string, pos float64) error {
+	s.l.Debug().Str("card", cardID).Str("list", listID).Float64("pos", pos).Msg("Moving card")
+	return s.update(func(d *document) error {
+		from, idx := d.card(cardID)
+		if from == nil {
+			return ErrCardNotFound
+		}
+		toIdx := d.list(listID)
+		if toIdx < 0 {
+			return ErrListNotFound
+		}
+		b := from.blocks[idx]
+		from.blocks = append(from.blocks[:idx], from.blocks[idx+1:]...)
+
+		to := d.lists[toIdx]
+		var cardIdx int
+		for _, other := range to.blocks {
+			if other.item && other.pos < pos {
+				cardIdx++
+			}
+		}
+		to.insertCard(cardIdx, b)
+		return nil
+	})
+}
+
+// CreateCard adds the card at the bottom of the list with the corresponding id, with its labels
+// as tags
+func (s *Source) CreateCard(_, listID string, c domain.Card) (domain.Card, error) {
+	s.l.Debug().Str("list", listID).Str("name", c.Name).Msg("Creating card")
+	b := newBlock(c)
+	err := s.updateList(listID, func(d *document, idx int) {
+		d.lists[idx].insertCard(-1, b)
+	})
+	if err != nil {
+		return domain.Card{}, err
+	}
+	created := parseCard(b.lines)
+	return domain.NewCard(b.id, b.number, created.name, created.description, b.pos, c.Labels, created.due), nil
+}
+
+// updateList applies the update to the list with the corresponding id
+func (s *Source) updateList(listID string, update func(d *document, idx int)) error {
+	return s.update(func(d *document) error {
+		idx := d.list(listID)
+		if idx < 0 {
+			return ErrListNotFound
+		}
+		update(d, idx)
+		return nil
+	})
+}
+
+// updateCard applies the update to the card with the corresponding id
+func (s *Source) updateCard(cardID string, update func(b *block)) error {
+	return s.update(func(d *document) error {
+		l, idx := d.card(cardID)
+		if l == nil {
+			return ErrCardNotFound
+		}
+		update(l.blocks[idx])
+		return nil
+	})
+}
+
+// update reads the file, applies the update and writes it back, so that changes made to the file
+// in the meantime are kept
+func (s *Source) update(update func(d *document) error) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	d, err := s.read()
+	if err != nil {
+		return err
+	}
+	if err := update(d); err != nil {
+		return err
+	}
+	d.separate()
+	d.index()
+	return s.write(d)
+}
+
+// read reads and parses the file
+func (s *Source) read() (*document, error) {
+	data, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read board file %s", s.path)
+	}
+	return parse(data), nil
+}
+
+// write replaces the file with the content of the document
+func (s *Source) write(d *document) error {
+	// the file is replaced atomically, so that it is never read partially written
+	tmp, err := ioutil.TempFile(filepath.Dir(s.path), "."+filepath.Base(s.path))
+	if err != nil {
+		return errors.Wrapf(err, "could not write board file %s", s.path)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(d.bytes()); err != nil {
+		tmp.Close()
+		return errors.Wrapf(err, "could not write board file %s", s.path)
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrapf(err, "could not write board file %s", s.path)
+	}
+	if info, err := os.Stat(s.path); err == nil {
+		_ = os.Chmod(tmp.Name(), info.Mode())
+	}
+	return errors.Wrapf(os.Rename(tmp.Name(), s.path), "could not write board file %s", s.path)
+}
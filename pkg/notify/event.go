@@ -0,0 +1,121 @@
+// Package notify detects changes to the cards the user cares about and delivers them as notifications
+package notify
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/giannimassi/trello-tui/pkg/domain"
+)
+
+// Kind describes what happened to the card
+type Kind string
+
+// Kinds of events
+const (
+	Moved      Kind = "moved"     // the card was moved to another list
+	Commented  Kind = "commented" // comments were added to the card
+	DueChanged Kind = "due"       // the due date was set, changed or removed
+)
+
+// Event describes a change to a card assigned to or watched by the user
+type Event struct {
+	Kind       Kind       `json:"kind"`
+	Time       time.Time  `json:"time"`
+	BoardID    string     `json:"board_id"`
+	Board      string     `json:"board"`
+	CardID     string     `json:"card_id"`
+	CardNumber int        `json:"card_number"`
+	Card       string     `json:"card"`
+	List       string     `json:"list"`
+	FromList   string     `json:"from_list,omitempty"` // list containing the card before it was moved
+	Due        *time.Time `json:"due,omitempty"`
+	Comments   int        `json:"comments,omitempty"` // number of comments added
+	Assigned   bool       `json:"assigned"`
+	Watched    bool       `json:"watched"`
+}
+
+// Message returns a short description of the event
+func (e Event) Message() string {
+	switch e.Kind {
+	case Moved:
+		return fmt.Sprintf("%s moved from %s to %s", e.Card, e.FromList, e.List)
+	case Commented:
+		if e.Comments == 1 {
+			return fmt.Sprintf("%s has a new comment", e.Card)
+		}
+		return fmt.Sprintf("%s has %d new comments", e.Card, e.Comments)
+	}
+	if e.Due == nil {
+		return fmt.Sprintf("%s due date removed", e.Card)
+	}
+	return fmt.Sprintf("%s due %s", e.Card, e.Due.Format("Mon Jan 2 15:04"))
+}
+
+// Detect returns the events concerning the cards assigned to or watched by the user among the
+// changes between the previous and the current version of the board
+func Detect(previous, b *domain.Board, changes []domain.Change, now time.Time) []Event {
+	var events []Event
+	for _, change := range changes {
+		if !change.IsCard() || change.Kind == domain.Added || change.Kind == domain.Removed {
+			continue
+		}
+		prev, found := previous.CardByID(change.CardID)
+		if !found {
+			continue
+		}
+		listIdx, found := b.CardList(change.CardID)
+		if !found {
+			continue
+		}
+		l := b.Lists[listIdx]
+		c := l.CardsByID[change.CardID]
+		if !c.Assigned && !c.Watched && !prev.Assigned && !prev.Watched {
+			continue
+		}
+
+		event := Event{
+			Time:       now,
+			BoardID:    b.ID,
+			Board:      b.Name,
+			CardID:     c.ID,
+			CardNumber: c.IdShort,
+			Card:       c.Name,
+			List:       l.Name,
+			Assigned:   c.Assigned,
+			Watched:    c.Watched,
+		}
+		if change.FromListID != "" {
+			e := event
+			e.Kind = Moved
+			if idx, found := previous.CardList(c.ID); found {
+				e.FromList = previous.Lists[idx].Name
+			}
+			events = append(events, e)
+		}
+		if c.Comments > prev.Comments {
+			e := event
+			e.Kind = Commented
+			e.Comments = c.Comments - prev.Comments
+			events = append(events, e)
+		}
+		if dueChanged(prev.Due, c.Due) {
+			e := event
+			e.Kind = DueChanged
+			if c.Due != nil {
+				due := c.Due.Time
+				e.Due = &due
+			}
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+// dueChanged returns true if the due date was set, changed or removed, regardless of its completion
+func dueChanged(previous, due *domain.Due) bool {
+	if previous == nil || due == nil {
+		return previous != due
+	}
+	return !previous.Time.Equal(due.Time)
+}
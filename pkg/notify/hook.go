@@ -0,0 +1,33 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os/exec"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// hookTimeout is the maximum time a hook is allowed to run for
+const hookTimeout = 10 * time.Second
+
+// RunHook executes the shell command with the event encoded as JSON on its standard input,
+// e.g. `jq -r .card | xargs notify-send`
+func RunHook(command string, e Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return errors.Wrap(err, "could not encode event")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdin = bytes.NewReader(data)
+	out, err := cmd.CombinedOutput()
+	if err != nil && len(out) > 0 {
+		return errors.Wrapf(err, "notification hook failed: %s", bytes.TrimSpace(out))
+	}
+	return errors.Wrap(err, "notification hook failed")
+}
@@ -0,0 +1,350 @@
+package state
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/giannimassi/trello-tui/pkg/domain"
+	"github.com/giannimassi/trello-tui/pkg/export"
+	"github.com/giannimassi/trello-tui/pkg/importer"
+	"github.com/giannimassi/trello-tui/pkg/store"
+)
+
+var (
+	// ErrBoardNotLoaded is returned when an action is requested before the board is loaded
+	ErrBoardNotLoaded = errors.New("board not loaded")
+	// ErrListNotFound is returned when an action is requested on a list which is not part of the board
+	ErrListNotFound = errors.New("list not found")
+	// ErrCardNotFound is returned when an action is requested on a card which is not part of the board
+	ErrCardNotFound = errors.New("card not found")
+	// ErrNoImport is returned when cards are imported before planning the import or while importing
+	ErrNoImport = errors.New("no import planned or import already running")
+	// ErrArchiveNotSupported is returned when archived cards are requested from a source which
+	// cannot load them
+	ErrArchiveNotSupported = errors.New("archived cards are not supported by the board source")
+)
+
+var _ store.Actions = &Updater{}
+
+// boardUpdate returns the updated board and the request to be executed for applying the same update remotely
+type boardUpdate func(b *domain.Board) (*domain.Board, func() error, error)
+
+// SwitchBoard replaces the current board with the board with the provided name and loads it
+func (u *Updater) SwitchBoard(name string) error {
+	u.l.Info().Str("board", name).Msg("Switching board")
+	u.switchBoard(name)
+	u.put(u.storable())
+	u.RequestRefresh()
+	return nil
+}
+
+// ToggleArchived shows or hides the archived cards of the board and reloads it, it returns true
+// if archived cards are shown
+func (u *Updater) ToggleArchived() (bool, error) {
+	if _, ok := u.source.(ArchiveSource); !ok {
+		return false, ErrArchiveNotSupported
+	}
+	u.BeginWrite()
+	u.showArchived = !u.showArchived
+	show := u.showArchived
+	name := u.cfg.SelectedBoard
+	u.EndWrite()
+	u.l.Info().Bool("show", show).Msg("Toggling archived cards")
+	// the board is reloaded from scratch so that the cards shown or hidden are not notified as changes
+	u.switchBoard(name)
+	u.put(u.storable())
+	u.RequestRefresh()
+	return show, nil
+}
+
+// AddList creates a new list with the provided name at the provided index
+func (u *Updater) AddList(idx int, name string) error {
+	if err := u.ensureSourceInitialized(); err != nil {
+		return err
+	}
+
+	u.BeginRead()
+	b := u.current()
+	u.EndRead()
+	if b == nil {
+		return ErrBoardNotLoaded
+	}
+
+	l, err := u.source.CreateList(b.ID, name, b.ListPos(idx))
+	if err != nil {
+		u.l.Error().Err(err).Str("name", name).Msg("Could not create list")
+		return err
+	}
+	return u.updateBoard(func(b *domain.Board) (*domain.Board, func() error, error) {
+		return b.WithList(idx, l), nil, nil
+	})
+}
+
+// RenameList sets the name of the list at the provided index
+func (u *Updater) RenameList(idx int, name string) error {
+	return u.updateList(idx, func(b *domain.Board, l domain.List) (*domain.Board, func() error) {
+		return b.WithListRenamed(idx, name), func() error { return u.source.RenameList(l.ID, name) }
+	})
+}
+
+// ArchiveList archives the list at the provided index
+func (u *Updater) ArchiveList(idx int) error {
+	return u.updateList(idx, func(b *domain.Board, l domain.List) (*domain.Board, func() error) {
+		return b.WithoutList(idx), func() error { return u.source.ArchiveList(l.ID) }
+	})
+}
+
+// MoveList moves the list at index from to index to
+func (u *Updater) MoveList(from, to int) error {
+	return u.updateList(from, func(b *domain.Board, l domain.List) (*domain.Board, func() error) {
+		if to < 0 || to >= len(b.Lists) || to == from {
+			return b, nil
+		}
+		newBoard := b.WithListMoved(from, to)
+		pos := newBoard.Lists[to].Pos
+		return newBoard, func() error { return u.source.MoveList(l.ID, pos) }
+	})
+}
+
+// SetCardDue sets the due date of the card with the corresponding id, a nil due removes the due date
+func (u *Updater) SetCardDue(id string, due *time.Time) error {
+	return u.updateCard(id, func(c *domain.Card) {
+		if due == nil {
+			c.Due = nil
+			return
+		}
+		newDue := domain.Due{Time: *due}
+		if c.Due != nil {
+			newDue.Complete = c.Due.Complete
+		}
+		c.Due = &newDue
+	}, func(c domain.Card) error {
+		return u.source.SetCardDue(c.ID, due)
+	})
+}
+
+// SetCardDueComplete marks the due date of the card with the corresponding id as complete or not
+func (u *Updater) SetCardDueComplete(id string, complete bool) error {
+	return u.updateCard(id, func(c *domain.Card) {
+		if c.Due != nil {
+			newDue := *c.Due
+			newDue.Complete = complete
+			c.Due = &newDue
+		}
+	}, func(c domain.Card) error {
+		return u.source.SetCardDueComplete(c.ID, complete)
+	})
+}
+
+// MoveCard moves the card with the corresponding id to the list at index listIdx, inserted at index cardIdx
+func (u *Updater) MoveCard(id string, listIdx, cardIdx int) error {
+	return u.updateBoard(func(b *domain.Board) (*domain.Board, func() error, error) {
+		newBoard, found := b.WithCardMoved(id, listIdx, cardIdx)
+		if !found {
+			return nil, nil, ErrCardNotFound
+		}
+		l := newBoard.Lists[listIdx]
+		c := l.CardsByID[id]
+		return newBoard, func() error { return u.source.MoveCard(c.ID, l.ID, c.Pos) }, nil
+	})
+}
+
+// ExportBoard writes the board to the file at the provided path, in the format corresponding to
+// its extension, or to a Markdown file named after the board if the path is empty
+func (u *Updater) ExportBoard(path string) (string, error) {
+	u.BeginRead()
+	b := u.current()
+	u.EndRead()
+	if b == nil {
+		return "", ErrBoardNotLoaded
+	}
+	if path == "" {
+		path = export.DefaultPath(b, time.Now())
+	}
+	if err := export.WriteFile(path, b, ""); err != nil {
+		u.l.Error().Err(err).Str("path", path).Msg("Could not export board")
+		return "", err
+	}
+	return path, nil
+}
+
+// PlanImport reads the cards of the file at the provided path for importing them into the list
+// at the provided index, matching their labels with the ones of the board
+func (u *Updater) PlanImport(path string, listIdx int) error {
+	u.setImport(store.ImportStatus{})
+	u.put(u.storable())
+	plan, err := u.planImport(path, listIdx)
+	if err != nil {
+		u.l.Error().Err(err).Str("path", path).Msg("Could not read cards to import")
+	}
+	u.setImport(store.ImportStatus{Plan: plan, Err: err})
+	u.put(u.storable())
+	return err
+}
+
+// planImport returns the plan for importing the cards of the file into the list
+func (u *Updater) planImport(path string, listIdx int) (*importer.Plan, error) {
+	if err := u.ensureSourceInitialized(); err != nil {
+		return nil, err
+	}
+	u.BeginRead()
+	b := u.current()
+	u.EndRead()
+	if b == nil {
+		return nil, ErrBoardNotLoaded
+	}
+	labels, err := u.source.Labels(b.ID)
+	if err != nil {
+		return nil, err
+	}
+	return importer.NewPlan(path, b, listIdx, labels)
+}
+
+// ImportCards creates the cards of the import planned by PlanImport, each card is added to the
+// board as soon as it is created
+func (u *Updater) ImportCards() error {
+	if err := u.ensureSourceInitialized(); err != nil {
+		return err
+	}
+
+	// the import is marked as running while holding the lock, so that it is not run twice
+	u.BeginWrite()
+	status := u.importStatus
+	if status.Plan == nil || status.Running {
+		u.EndWrite()
+		return ErrNoImport
+	}
+	plan := status.Plan
+	status = store.ImportStatus{Plan: plan, Running: true, Runs: status.Runs + 1}
+	u.importStatus = status
+	u.edit(func(b board) { b.setImport(status) })
+	u.EndWrite()
+	u.put(u.storable())
+	err := plan.Run(func(c domain.Card) (domain.Card, error) {
+		return u.source.CreateCard(plan.BoardID, plan.ListID, c)
+	}, func(c domain.Card) {
+		status.Created++
+		u.addCard(plan.BoardID, plan.ListID, c)
+		u.setImport(status)
+		u.put(u.storable())
+	})
+	if err != nil {
+		u.l.Error().Err(err).Str("path", plan.Path).Msg("Could not import cards")
+	}
+	status.Running = false
+	status.Err = err
+	u.setImport(status)
+	u.put(u.storable())
+	return err
+}
+
+// addCard adds the card created remotely to the list with the corresponding id, if the board
+// with the corresponding id is the current one
+func (u *Updater) addCard(boardID, listID string, c domain.Card) {
+	u.BeginWrite()
+	defer u.EndWrite()
+	b := u.current()
+	if b == nil || b.ID != boardID {
+		return
+	}
+	for i, l := range b.Lists {
+		if l.ID == listID {
+			newBoard, _ := b.WithCardAdded(i, c)
+			u.board = u.online(newBoard)
+			return
+		}
+	}
+}
+
+// MarkNotificationsRead marks all the notifications of the inbox as read
+func (u *Updater) MarkNotificationsRead() {
+	u.markNotificationsRead()
+	u.put(u.storable())
+}
+
+// LoadMemberNotifications fetches the latest trello notifications of the user
+func (u *Updater) LoadMemberNotifications() error {
+	err := u.ensureSourceInitialized()
+	var notifications []domain.Notification
+	if source, ok := u.source.(NotificationSource); ok && err == nil {
+		notifications, err = source.Notifications()
+	}
+	if err != nil {
+		u.l.Error().Err(err).Msg("Could not load notifications")
+	}
+	u.setMemberNotifications(notifications, err)
+	u.put(u.storable())
+	return err
+}
+
+// MarkMemberNotificationRead marks the trello notification with the corresponding id as read
+func (u *Updater) MarkMemberNotificationRead(id string) error {
+	if err := u.ensureSourceInitialized(); err != nil {
+		return err
+	}
+	source, ok := u.source.(NotificationSource)
+	if !ok {
+		return nil
+	}
+	u.setMemberNotificationRead(id)
+	u.put(u.storable())
+	return source.MarkNotificationRead(id)
+}
+
+// updateList applies the update to the list at the provided index
+func (u *Updater) updateList(idx int, update func(b *domain.Board, l domain.List) (*domain.Board, func() error)) error {
+	return u.updateBoard(func(b *domain.Board) (*domain.Board, func() error, error) {
+		if idx < 0 || idx >= len(b.Lists) {
+			return nil, nil, ErrListNotFound
+		}
+		newBoard, request := update(b, b.Lists[idx])
+		return newBoard, request, nil
+	})
+}
+
+// updateCard applies the update to the card with the corresponding id
+func (u *Updater) updateCard(id string, update func(c *domain.Card), request func(c domain.Card) error) error {
+	return u.updateBoard(func(b *domain.Board) (*domain.Board, func() error, error) {
+		newBoard, found := b.WithCard(id, update)
+		if !found {
+			return nil, nil, ErrCardNotFound
+		}
+		c, _ := newBoard.CardByID(id)
+		return newBoard, func() error { return request(c) }, nil
+	})
+}
+
+// updateBoard applies the update to the board optimistically and then executes the request,
+// if the request fails the board is marked as offline until the next refresh
+func (u *Updater) updateBoard(update boardUpdate) error {
+	if err := u.ensureSourceInitialized(); err != nil {
+		return err
+	}
+
+	u.BeginWrite()
+	b := u.current()
+	if b == nil {
+		u.EndWrite()
+		return ErrBoardNotLoaded
+	}
+	newBoard, request, err := update(b)
+	if err != nil {
+		u.EndWrite()
+		return err
+	}
+	u.board = u.online(newBoard)
+	u.EndWrite()
+	u.put(u.storable())
+
+	if request == nil {
+		return nil
+	}
+	if err := request(); err != nil {
+		u.l.Error().Err(err).Msg("Could not update board")
+		u.setBoardOffline(err)
+		u.put(u.storable())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package state
+
+import "time"
+
+// idleThreshold is the time without user activity after which the user is considered idle
+const idleThreshold = time.Minute
+
+// refreshInterval returns the time to wait before the next refresh. Without adaptive refresh the
+// configured interval is always used, otherwise the configured interval is used while the user is
+// active and it is doubled for every minute of inactivity and for every consecutive failure, up
+// to the configured maximum.
+func refreshInterval(cfg *Config, idle time.Duration, failures int) time.Duration {
+	interval := cfg.BoardRefreshInterval
+	if !cfg.AdaptiveRefresh {
+		return interval
+	}
+	steps := failures
+	if idle >= idleThreshold {
+		steps += int(idle / idleThreshold)
+	}
+	for i := 0; i < steps && interval < cfg.MaxRefreshInterval; i++ {
+		interval *= 2
+	}
+	if interval > cfg.MaxRefreshInterval {
+		interval = cfg.MaxRefreshInterval
+	}
+	return interval
+}
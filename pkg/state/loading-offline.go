@@ -1,6 +1,9 @@
 package state
 
-import "github.com/giannimassi/trello-tui/pkg/domain"
+import (
+	"github.com/giannimassi/trello-tui/pkg/domain"
+	"github.com/giannimassi/trello-tui/pkg/store"
+)
 
 type boardLoadingOffline struct {
 	boardLoading
@@ -12,7 +15,14 @@ func (b *boardLoadingOffline) HeaderTitle() string {
 }
 
 func (b *boardLoadingOffline) HeaderSubtitle() string {
-	return "Could not load board.\nDetails:\n" + b.err.Error()
+	return "Could not load board, check the board name and the trello credentials. Details: " + b.err.Error()
+}
+
+func (b *boardLoadingOffline) SyncStatus() store.SyncStatus {
+	if b.fetching {
+		return store.SyncRetrying
+	}
+	return store.SyncOffline
 }
 
 func (b *boardLoadingOffline) online(newBoard *domain.Board) board {
@@ -23,6 +33,12 @@ func (b *boardLoadingOffline) online(newBoard *domain.Board) board {
 }
 
 func (b *boardLoadingOffline) offline(err error) board {
-	b.err = err
-	return b
+	offline := *b
+	offline.err = err
+	return &offline
+}
+
+func (b *boardLoadingOffline) clone() board {
+	c := *b
+	return &c
 }
@@ -1,11 +1,30 @@
 package state
 
 import (
+	"time"
+
 	"github.com/giannimassi/trello-tui/pkg/domain"
+	"github.com/giannimassi/trello-tui/pkg/notify"
+	"github.com/giannimassi/trello-tui/pkg/store"
 )
 
+// changesRetention is how long the time of a card change is kept for
+const changesRetention = time.Minute
+
 type boardLoading struct {
 	boardName string
+	fetching  bool
+	interval  time.Duration
+	refreshed time.Time            // time of the last successful refresh
+	changed   map[string]time.Time // time of the last change of the recently changed cards, by id
+
+	notifications []notify.Event // newest first
+	unread        int            // number of notifications not read yet, which are the first ones
+
+	memberNotifications    []domain.Notification // trello notifications of the user, nil until loaded
+	memberNotificationsErr error
+
+	importStatus store.ImportStatus
 }
 
 var _ board = &boardLoading{}
@@ -25,11 +44,71 @@ func (b *boardLoading) offline(err error) board {
 	return offline
 }
 
-func (b *boardLoading) HeaderTitle() string         { return b.boardName + " - loading" }
-func (b *boardLoading) HeaderSubtitle() string      { return "..." }
-func (b *boardLoading) ListName(idx int) string     { return "Loading..." }
-func (b *boardLoading) ListCardsIds(idx int) []int  { return nil }
-func (b *boardLoading) CardName(id int) string      { return "" }
-func (b *boardLoading) CardLabelsStr(id int) string { return "" }
-func (b *boardLoading) Description(id int) string   { return "" }
-func (b *boardLoading) ListsLen() int               { return 0 }
+func (b *boardLoading) clone() board {
+	c := *b
+	return &c
+}
+
+func (b *boardLoading) HeaderTitle() string                     { return b.boardName + " - loading" }
+func (b *boardLoading) HeaderSubtitle() string                  { return "..." }
+func (b *boardLoading) ListName(idx int) string                 { return "Loading..." }
+func (b *boardLoading) ListCardsIds(idx int) []string           { return nil }
+func (b *boardLoading) CardIdShort(id string) int               { return 0 }
+func (b *boardLoading) CardName(id string) string               { return "" }
+func (b *boardLoading) CardLabels(id string) []domain.CardLabel { return nil }
+func (b *boardLoading) Description(id string) string            { return "" }
+func (b *boardLoading) ListsLen() int                           { return 0 }
+func (b *boardLoading) CardDue(id string) (domain.Due, bool)    { return domain.Due{}, false }
+func (b *boardLoading) CardList(id string) (int, bool)          { return 0, false }
+func (b *boardLoading) CardURL(id string) string                { return "" }
+func (b *boardLoading) CardArchived(id string) bool             { return false }
+func (b *boardLoading) current() *domain.Board                  { return nil }
+func (b *boardLoading) SyncStatus() store.SyncStatus            { return store.SyncFetching }
+func (b *boardLoading) LastRefresh() time.Time                  { return b.refreshed }
+func (b *boardLoading) CardChangedAt(id string) time.Time       { return b.changed[id] }
+func (b *boardLoading) BoardCounts() (lists, cards int)         { return 0, 0 }
+func (b *boardLoading) RefreshInterval() time.Duration          { return b.interval }
+func (b *boardLoading) setFetching(fetching bool)               { b.fetching = fetching }
+func (b *boardLoading) setRefreshInterval(d time.Duration)      { b.interval = d }
+func (b *boardLoading) setRefreshed(t time.Time)                { b.refreshed = t }
+func (b *boardLoading) Notifications() []notify.Event           { return b.notifications }
+func (b *boardLoading) UnreadNotifications() int                { return b.unread }
+
+func (b *boardLoading) MemberNotifications() []domain.Notification { return b.memberNotifications }
+func (b *boardLoading) MemberNotificationsErr() error              { return b.memberNotificationsErr }
+
+// setMemberNotifications replaces the trello notifications of the user and the error of the last
+// attempt at loading them
+func (b *boardLoading) setMemberNotifications(notifications []domain.Notification, err error) {
+	b.memberNotifications = notifications
+	b.memberNotificationsErr = err
+}
+
+func (b *boardLoading) Import() store.ImportStatus { return b.importStatus }
+
+// setImport replaces the status of the import
+func (b *boardLoading) setImport(status store.ImportStatus) {
+	b.importStatus = status
+}
+
+// setNotifications replaces the notifications of the inbox, which are shared across boards
+func (b *boardLoading) setNotifications(notifications []notify.Event, unread int) {
+	b.notifications = notifications
+	b.unread = unread
+}
+
+// setChanged records the time at which the cards were changed, forgetting old changes
+func (b *boardLoading) setChanged(changes []domain.Change, t time.Time) {
+	changed := make(map[string]time.Time, len(b.changed)+len(changes))
+	for id, changedAt := range b.changed {
+		if t.Sub(changedAt) < changesRetention {
+			changed[id] = changedAt
+		}
+	}
+	for _, c := range changes {
+		if c.IsCard() && c.Kind != domain.Removed {
+			changed[c.CardID] = t
+		}
+	}
+	b.changed = changed
+}
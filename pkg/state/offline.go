@@ -1,17 +1,31 @@
 package state
 
+import "github.com/giannimassi/trello-tui/pkg/store"
+
 type boardOffline struct {
 	boardOnline
 	err error
 }
 
+func (b *boardOffline) clone() board {
+	c := *b
+	return &c
+}
+
 func (b *boardOffline) HeaderTitle() string {
-	return b.boardName + " - offline"
+	return b.Board.Name + " - offline"
 }
 
 func (b *boardOffline) HeaderSubtitle() string {
 	if b.err != nil {
-		return "Could not load board.\nDetails:\n" + b.err.Error()
+		return "Could not refresh board, showing the last version loaded. Details: " + b.err.Error()
+	}
+	return "Could not refresh board (unknown error), showing the last version loaded."
+}
+
+func (b *boardOffline) SyncStatus() store.SyncStatus {
+	if b.fetching {
+		return store.SyncRetrying
 	}
-	return "Could not load board (unknown error)."
+	return store.SyncOffline
 }
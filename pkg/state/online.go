@@ -2,9 +2,9 @@ package state
 
 import (
 	"fmt"
-	"strings"
 
 	"github.com/giannimassi/trello-tui/pkg/domain"
+	"github.com/giannimassi/trello-tui/pkg/store"
 	"github.com/rs/zerolog/log"
 )
 
@@ -14,8 +14,9 @@ type boardOnline struct {
 }
 
 func (b *boardOnline) online(newBoard *domain.Board) board {
-	b.Board = newBoard
-	return b
+	online := *b
+	online.Board = newBoard
+	return &online
 }
 
 func (b *boardOnline) offline(err error) board {
@@ -26,14 +27,33 @@ func (b *boardOnline) offline(err error) board {
 	return offline
 }
 
+func (b *boardOnline) clone() board {
+	c := *b
+	return &c
+}
+
 func (b *boardOnline) HeaderTitle() string {
-	return b.boardName + " - online"
+	return b.Board.Name + " - online"
 }
 
 func (b *boardOnline) HeaderSubtitle() string {
 	return b.Board.Description
 }
 
+func (b *boardOnline) SyncStatus() store.SyncStatus {
+	if b.fetching {
+		return store.SyncFetching
+	}
+	return store.SyncIdle
+}
+
+func (b *boardOnline) BoardCounts() (lists, cards int) {
+	for _, l := range b.Board.Lists {
+		cards += len(l.CartIds)
+	}
+	return len(b.Board.Lists), cards
+}
+
 func (b *boardOnline) ListName(idx int) string {
 	if idx >= len(b.Board.Lists) {
 		return ""
@@ -41,49 +61,76 @@ func (b *boardOnline) ListName(idx int) string {
 	return b.Board.Lists[idx].Name
 }
 
-func (b *boardOnline) ListCardsIds(idx int) []int {
+func (b *boardOnline) ListCardsIds(idx int) []string {
 	if idx >= len(b.Board.Lists) {
-		return []int{}
+		return []string{}
 	}
 	return b.Board.Lists[idx].CartIds
 }
 
-func (b *boardOnline) CardName(id int) string {
+func (b *boardOnline) CardName(id string) string {
 	c, found := b.Board.CardByID(id)
 	if !found {
-		log.Error().Int("id", id).Msg("Card not found")
+		log.Error().Str("id", id).Msg("Card not found")
 		return ""
 	}
 	return fmt.Sprintf("%s", c.Name)
 }
 
-func (b *boardOnline) CardLabelsStr(id int) string {
+func (b *boardOnline) CardLabels(id string) []domain.CardLabel {
 	c, found := b.Board.CardByID(id)
 	if !found {
-		log.Error().Int("id", id).Msg("Card not found")
-		return ""
+		log.Error().Str("id", id).Msg("Card not found")
+		return nil
 	}
-	var strs []string
-	for _, lbl := range c.Labels {
-		strs = append(strs, "[black:"+lbl.Color+"] "+lbl.Name+" [-:-:-]")
-	}
-	return strings.Join(strs, " ")
+	return c.Labels
+}
+
+func (b *boardOnline) CardIdShort(id string) int {
+	c, _ := b.Board.CardByID(id)
+	return c.IdShort
 }
 
-func (b *boardOnline) Description(id int) string {
+func (b *boardOnline) CardURL(id string) string {
+	c, _ := b.Board.CardByID(id)
+	return c.URL
+}
+
+func (b *boardOnline) CardArchived(id string) bool {
+	c, _ := b.Board.CardByID(id)
+	return c.Archived
+}
+
+func (b *boardOnline) CardList(id string) (int, bool) {
+	return b.Board.CardList(id)
+}
+
+func (b *boardOnline) Description(id string) string {
 	c, found := b.Board.CardByID(id)
 	if !found {
-		log.Error().Int("id", id).Msg("Card not found")
+		log.Error().Str("id", id).Msg("Card not found")
 		return ""
 	}
 	return c.Description
 }
 
+func (b *boardOnline) CardDue(id string) (domain.Due, bool) {
+	c, found := b.Board.CardByID(id)
+	if !found || c.Due == nil {
+		return domain.Due{}, false
+	}
+	return *c.Due, true
+}
+
+func (b *boardOnline) current() *domain.Board {
+	return b.Board
+}
+
 func (b *boardOnline) ListsLen() int {
 	return len(b.Board.Lists)
 }
 
-func cardIndexInListFromID(cardIds []int, id int) (int, bool) {
+func cardIndexInListFromID(cardIds []string, id string) (int, bool) {
 	for i, cardID := range cardIds {
 		if cardID == id {
 			return i, true
@@ -0,0 +1,74 @@
+package state
+
+import (
+	"context"
+	"time"
+
+	"github.com/giannimassi/trello-tui/pkg/domain"
+	"github.com/giannimassi/trello-tui/pkg/trello"
+)
+
+// BoardSource loads boards and applies the changes made by the user, e.g. via the trello API or
+// to a local file
+type BoardSource interface {
+	// Init prepares the source for use, it is retried before each request until it succeeds
+	Init() error
+	// Board returns the latest version of the board with the provided name
+	Board(name string) (*domain.Board, error)
+	// Boards returns the names of the boards available
+	Boards() ([]string, error)
+	// Labels returns the labels available on the board with the corresponding id
+	Labels(boardID string) ([]domain.CardLabel, error)
+	ListSource
+	CardSource
+}
+
+// ListSource applies changes to the lists of a board
+type ListSource interface {
+	CreateList(boardID, name string, pos float64) (domain.List, error)
+	RenameList(listID, name string) error
+	ArchiveList(listID string) error
+	MoveList(listID string, pos float64) error
+}
+
+// CardSource applies changes to the cards of a board
+type CardSource interface {
+	// CreateCard creates the card at the bottom of the list, with the labels and members of the
+	// board matching the names of the ones of the card, and returns the card created
+	CreateCard(boardID, listID string, c domain.Card) (domain.Card, error)
+	SetCardDue(cardID string, due *time.Time) error
+	SetCardDueComplete(cardID string, complete bool) error
+	MoveCard(cardID, listID string, pos float64) error
+}
+
+// NotificationSource is implemented by the sources providing notifications for the user, e.g. trello
+type NotificationSource interface {
+	Notifications() ([]domain.Notification, error)
+	MarkNotificationRead(notificationID string) error
+}
+
+// ArchiveSource is implemented by the sources which can load archived cards, e.g. trello
+type ArchiveSource interface {
+	// BoardWithArchived returns the latest version of the board with the provided name, including
+	// its archived cards
+	BoardWithArchived(name string) (*domain.Board, error)
+}
+
+// CommentSource is implemented by the sources supporting comments on cards, e.g. trello
+type CommentSource interface {
+	AddComment(cardID, text string) error
+}
+
+// WatchingSource is implemented by the sources which can tell when the boards change, e.g. files
+// edited outside of the application
+type WatchingSource interface {
+	// Watch calls changed whenever the boards change, until the context is done
+	Watch(ctx context.Context, changed func())
+}
+
+var (
+	_ BoardSource        = &trello.Client{}
+	_ NotificationSource = &trello.Client{}
+	_ CommentSource      = &trello.Client{}
+	_ ArchiveSource      = &trello.Client{}
+)
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/giannimassi/trello-tui/pkg/domain"
+	"github.com/giannimassi/trello-tui/pkg/notify"
 	"github.com/giannimassi/trello-tui/pkg/store"
 
 	"github.com/giannimassi/trello-tui/pkg/trello"
@@ -13,74 +14,256 @@ import (
 // Config is the state configuration, including trello authentication details
 type Config struct {
 	Trello               trello.Config
+	Source               BoardSource // source of the boards, a trello client configured by Trello if nil
 	SelectedBoard        string
 	BoardRefreshInterval time.Duration
+	AdaptiveRefresh      bool          // poll less often while the user is idle or refreshes fail
+	MaxRefreshInterval   time.Duration // maximum interval used by adaptive refresh
+	NotifyCommand        string        // shell command executed for each notification, with the event as JSON on stdin
 }
 
-// board is the interface used for managing state behaviour changes
+// maxNotifications is the number of notifications kept in the inbox
+const maxNotifications = 100
+
+// board is the interface used for managing state behaviour changes, the board published to the
+// store must not be modified: the setters are only called on copies returned by clone
 type board interface {
 	online(*domain.Board) board
 	offline(err error) board
+	clone() board
+	current() *domain.Board
+	setFetching(fetching bool)
+	setRefreshInterval(interval time.Duration)
+	setRefreshed(t time.Time)
+	setChanged(changes []domain.Change, t time.Time)
+	setNotifications(notifications []notify.Event, unread int)
+	setMemberNotifications(notifications []domain.Notification, err error)
+	setImport(status store.ImportStatus)
 }
 
 // state implements github.com/giannimassi/trello-tui/pkg/gui `gui.State`
 type state struct {
-	cfg    *Config
-	client *trello.Client
+	cfg      *Config
+	source   BoardSource
+	interval time.Duration // current refresh interval
 	board
-	m sync.RWMutex
+	notifications []notify.Event // newest first, shared across boards
+	unread        int
+
+	memberNotifications    []domain.Notification
+	memberNotificationsErr error
+	importStatus           store.ImportStatus // import being previewed or run, shared across boards
+	showArchived           bool               // archived cards are loaded along with the open ones
+	m                      sync.RWMutex
+	sourceReady            bool // the source has been initialized
+	sourceM                sync.Mutex
 }
 
 // newState returns a new instance of state
 func newState(cfg *Config) *state {
+	source := cfg.Source
+	if source == nil {
+		source = trello.NewClient(&cfg.Trello)
+	}
 	return &state{
-		cfg: cfg,
+		cfg:    cfg,
+		source: source,
 		board: &boardLoading{
 			boardName: cfg.SelectedBoard,
 		},
 	}
 }
 
-// ensureClientInitialized checks that the client has been initialized
-func (s *state) ensureClientInitialized() error {
-	if s.client == nil {
-		client := trello.NewClient(&s.cfg.Trello)
-		if err := client.Init(); err != nil {
+// ensureSourceInitialized checks that the source has been initialized
+func (s *state) ensureSourceInitialized() error {
+	s.sourceM.Lock()
+	defer s.sourceM.Unlock()
+	if !s.sourceReady {
+		if err := s.source.Init(); err != nil {
 			return err
 		}
-		s.client = client
+		s.sourceReady = true
 	}
 	return nil
 }
 
-// update returns a copy of the current state with updated board / err
-func (s *state) update() (*state, error) {
-	err := s.ensureClientInitialized()
+// update loads the latest version of the board, it returns the notifications for the changes to
+// the board and true if the state changed, including changes of the synchronization status
+func (s *state) update() ([]notify.Event, bool, error) {
+	err := s.ensureSourceInitialized()
 	if err != nil {
-		s.setBoardOffline(err)
-		return s, err
+		return nil, s.setBoardOffline(err), err
+	}
+	s.BeginRead()
+	boardName := s.cfg.SelectedBoard
+	load := s.source.Board
+	if archive, ok := s.source.(ArchiveSource); ok && s.showArchived {
+		load = archive.BoardWithArchived
+	}
+	s.EndRead()
+	b, err := load(boardName)
+	if s.switched(boardName) {
+		// the board was switched while loading, the result is discarded
+		return nil, false, nil
 	}
-	b, err := s.client.Board(s.cfg.SelectedBoard)
 	if err != nil {
-		s.setBoardOffline(err)
-		return s, err
+		return nil, s.setBoardOffline(err), err
+	}
+	events, changed := s.setBoardOnline(b)
+	return events, changed, nil
+}
+
+// switchBoard replaces the current board with the loading state for the board with the provided name
+func (s *state) switchBoard(name string) {
+	s.BeginWrite()
+	s.cfg.SelectedBoard = name
+	s.board = &boardLoading{
+		boardName:              name,
+		interval:               s.interval,
+		notifications:          s.notifications,
+		unread:                 s.unread,
+		memberNotifications:    s.memberNotifications,
+		memberNotificationsErr: s.memberNotificationsErr,
+		importStatus:           s.importStatus,
 	}
-	s.setBoardOnline(b)
-	return s, nil
+	s.EndWrite()
+}
+
+// switched returns true if the selected board is not the one with the provided name
+func (s *state) switched(name string) bool {
+	s.BeginRead()
+	defer s.EndRead()
+	return s.cfg.SelectedBoard != name
 }
 
-func (s *state) setBoardOnline(b *domain.Board) {
+// setBoardOnline replaces the board with the version provided unless nothing changed, it returns
+// the notifications for the changes compared to the previous version and true if the state changed,
+// which is always the case since the time of the last refresh is updated
+func (s *state) setBoardOnline(b *domain.Board) ([]notify.Event, bool) {
 	s.BeginWrite()
+	defer s.EndWrite()
+	now := time.Now()
+	previous := s.board.current()
+	_, wasOnline := s.board.(*boardOnline)
+	changes := domain.Diff(previous, b)
+	if wasOnline && len(changes) == 0 {
+		s.edit(func(b board) {
+			b.setFetching(false)
+			b.setRefreshed(now)
+		})
+		return nil, true
+	}
+
 	s.board = s.online(b)
+	s.board.setFetching(false)
+	s.board.setRefreshed(now)
+	if previous == nil {
+		// the first version loaded is not a change
+		return nil, true
+	}
+	s.board.setChanged(changes, now)
+	events := notify.Detect(previous, b, changes, now)
+	s.addNotifications(events)
+	return events, true
+}
+
+// addNotifications adds the events to the inbox as unread notifications, forgetting the oldest ones
+func (s *state) addNotifications(events []notify.Event) {
+	if len(events) == 0 {
+		return
+	}
+	notifications := make([]notify.Event, 0, len(events)+len(s.notifications))
+	for i := len(events) - 1; i >= 0; i-- {
+		notifications = append(notifications, events[i])
+	}
+	notifications = append(notifications, s.notifications...)
+	if len(notifications) > maxNotifications {
+		notifications = notifications[:maxNotifications]
+	}
+	s.notifications = notifications
+	s.unread += len(events)
+	if s.unread > len(notifications) {
+		s.unread = len(notifications)
+	}
+	s.edit(func(b board) { b.setNotifications(s.notifications, s.unread) })
+}
+
+// markNotificationsRead marks all the notifications of the inbox as read
+func (s *state) markNotificationsRead() {
+	s.BeginWrite()
+	s.unread = 0
+	s.edit(func(b board) { b.setNotifications(s.notifications, s.unread) })
 	s.EndWrite()
 }
 
-func (s *state) setBoardOffline(err error) {
+// setBoardOffline marks the board as offline, it returns true if the board was not offline already
+func (s *state) setBoardOffline(err error) bool {
 	s.BeginWrite()
+	defer s.EndWrite()
+	status := s.storable().SyncStatus()
 	s.board = s.board.offline(err)
+	s.board.setFetching(false)
+	return status != store.SyncOffline && status != store.SyncRetrying
+}
+
+// setRefreshInterval sets the interval used for refreshing the board
+func (s *state) setRefreshInterval(interval time.Duration) {
+	s.BeginWrite()
+	s.interval = interval
+	s.edit(func(b board) { b.setRefreshInterval(interval) })
+	s.EndWrite()
+}
+
+// setFetching marks the board as being loaded
+func (s *state) setFetching() {
+	s.BeginWrite()
+	s.edit(func(b board) { b.setFetching(true) })
+	s.EndWrite()
+}
+
+// setMemberNotifications replaces the trello notifications of the user, unless they could not be
+// loaded, in which case the error is recorded and the previous ones are kept
+func (s *state) setMemberNotifications(notifications []domain.Notification, err error) {
+	s.BeginWrite()
+	if err == nil {
+		s.memberNotifications = notifications
+	}
+	s.memberNotificationsErr = err
+	s.edit(func(b board) { b.setMemberNotifications(s.memberNotifications, s.memberNotificationsErr) })
 	s.EndWrite()
 }
 
+// setMemberNotificationRead marks the trello notification with the corresponding id as read
+func (s *state) setMemberNotificationRead(id string) {
+	s.BeginWrite()
+	notifications := make([]domain.Notification, len(s.memberNotifications))
+	copy(notifications, s.memberNotifications)
+	for i := range notifications {
+		if notifications[i].ID == id {
+			notifications[i].Unread = false
+		}
+	}
+	s.memberNotifications = notifications
+	s.edit(func(b board) { b.setMemberNotifications(s.memberNotifications, s.memberNotificationsErr) })
+	s.EndWrite()
+}
+
+// setImport replaces the status of the import
+func (s *state) setImport(status store.ImportStatus) {
+	s.BeginWrite()
+	s.importStatus = status
+	s.edit(func(b board) { b.setImport(status) })
+	s.EndWrite()
+}
+
+// edit replaces the board with a copy modified by the function, so that the version already
+// published to the store is not changed while the gui reads it, the caller must hold the write lock
+func (s *state) edit(f func(b board)) {
+	b := s.board.clone()
+	f(b)
+	s.board = b
+}
+
 func (s *state) storable() store.State {
 	return s.board.(store.State)
 }
@@ -2,8 +2,10 @@ package state
 
 import (
 	"context"
+	"sync"
 	"time"
 
+	"github.com/giannimassi/trello-tui/pkg/notify"
 	"github.com/giannimassi/trello-tui/pkg/store"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
@@ -16,41 +18,122 @@ type Updater struct {
 
 	*state
 	put store.PutStateFunc
+
+	refresh      chan struct{} // requests for refreshing the board immediately
+	failures     int           // number of consecutive failed refreshes
+	lastActivity time.Time
+	activityM    sync.Mutex
 }
 
 // NewUpdater returns a new instance of Updater
 func NewUpdater(cfg *Config, put store.PutStateFunc) *Updater {
 	u := Updater{
-		l:     log.Logger.With().Str("m", "state-update").Logger(),
-		cfg:   cfg,
-		state: newState(cfg),
-		put:   put,
+		l:            log.Logger.With().Str("m", "state-update").Logger(),
+		cfg:          cfg,
+		state:        newState(cfg),
+		put:          put,
+		refresh:      make(chan struct{}, 1),
+		lastActivity: time.Now(),
 	}
 	log.Info().Msg("updating state init")
 	put(u.storable())
 	return &u
 }
 
-// Run executes the loop exuting the requests via the trello client
+// Run executes the loop exuting the requests via the board source
 func (u *Updater) Run(ctx context.Context) {
-	var (
-		t   = time.NewTimer(0)
-		err error
-	)
+	t := time.NewTimer(0)
 	u.l.Debug().Msg("Refresh state started")
+	if source, ok := u.source.(WatchingSource); ok {
+		go source.Watch(ctx, u.RequestRefresh)
+	}
 	for {
+		// the fetching status is displayed only for refreshes requested explicitly, so that the
+		// state is not pushed twice on every tick
+		var requested bool
 		select {
 		case <-ctx.Done():
 			t.Stop()
 			return
 
-		case now := <-t.C:
-			u.l.Debug().Msg("Refreshing state")
-			if _, err = u.update(); err != nil {
-				u.l.Error().Err(err).Msg("Could not update board")
+		case <-u.refresh:
+			requested = true
+			if !t.Stop() {
+				select {
+				case <-t.C:
+				default:
+				}
 			}
+
+		case <-t.C:
+		}
+
+		u.l.Debug().Msg("Refreshing state")
+		if requested {
+			u.setFetching()
+			u.put(u.storable())
+		}
+		events, changed, err := u.update()
+		if err != nil {
+			u.l.Error().Err(err).Msg("Could not update board")
+			u.failures++
+		} else {
+			u.failures = 0
+		}
+		if len(events) > 0 {
+			u.l.Debug().Int("events", len(events)).Msg("Cards changed")
+			go u.runHooks(events)
+		}
+
+		interval := refreshInterval(u.cfg, u.idle(), u.failures)
+		if interval != u.interval {
+			u.setRefreshInterval(interval)
+			changed = true
+		}
+		if changed || requested {
 			u.put(u.storable())
-			t.Reset(u.cfg.BoardRefreshInterval - time.Since(now))
+		}
+		t.Reset(interval)
+	}
+}
+
+// RequestRefresh asks for the board to be refreshed as soon as possible, without waiting for the
+// refresh interval to elapse
+func (u *Updater) RequestRefresh() {
+	select {
+	case u.refresh <- struct{}{}:
+	default:
+		// a refresh is already pending
+	}
+}
+
+// ReportActivity records that the user is interacting with the application, if the user was
+// idle the board is refreshed immediately since the refresh interval may have grown
+func (u *Updater) ReportActivity() {
+	u.activityM.Lock()
+	wasIdle := time.Since(u.lastActivity) >= idleThreshold
+	u.lastActivity = time.Now()
+	u.activityM.Unlock()
+	if wasIdle && u.cfg.AdaptiveRefresh {
+		u.RequestRefresh()
+	}
+}
+
+// idle returns the time elapsed since the last user activity
+func (u *Updater) idle() time.Duration {
+	u.activityM.Lock()
+	defer u.activityM.Unlock()
+	return time.Since(u.lastActivity)
+}
+
+// runHooks executes the notification command configured for each event, one at a time
+func (u *Updater) runHooks(events []notify.Event) {
+	if u.cfg.NotifyCommand == "" {
+		return
+	}
+	for _, e := range events {
+		if err := notify.RunHook(u.cfg.NotifyCommand, e); err != nil {
+			u.l.Error().Err(err).Str("card", e.CardID).Msg("Could not run notification hook")
 		}
 	}
 }
@@ -0,0 +1,52 @@
+package store
+
+import "time"
+
+// Actions describes the interface required by the gui for modifying the board
+type Actions interface {
+	BoardActions
+	ListActions
+	CardActions
+	NotificationActions
+}
+
+// BoardActions describes the interface required for changing the board displayed
+type BoardActions interface {
+	SwitchBoard(name string) error
+	RequestRefresh()
+	ReportActivity()
+	// ExportBoard writes the board to the file at the provided path, or to a file named after the
+	// board if the path is empty, and returns the path of the file written
+	ExportBoard(path string) (string, error)
+	// PlanImport reads the cards of the file at the provided path for importing them into the
+	// list at the provided index, the plan is made available for preview via ImportState
+	PlanImport(path string, listIdx int) error
+	// ImportCards creates the cards of the import planned by PlanImport
+	ImportCards() error
+	// ToggleArchived shows or hides the archived cards of the board, it returns true if archived
+	// cards are shown
+	ToggleArchived() (bool, error)
+}
+
+// ListActions describes the interface required for modifying the board's lists
+type ListActions interface {
+	AddList(idx int, name string) error
+	RenameList(idx int, name string) error
+	ArchiveList(idx int) error
+	MoveList(from, to int) error
+}
+
+// CardActions describes the interface required for modifying the selected card
+type CardActions interface {
+	SetCardDue(id string, due *time.Time) error
+	SetCardDueComplete(id string, complete bool) error
+	MoveCard(id string, listIdx, cardIdx int) error
+}
+
+// NotificationActions describes the interface required for the notifications inbox and the
+// trello notifications of the user
+type NotificationActions interface {
+	MarkNotificationsRead()
+	LoadMemberNotifications() error
+	MarkMemberNotificationRead(id string) error
+}
@@ -1,5 +1,13 @@
 package store
 
+import (
+	"time"
+
+	"github.com/giannimassi/trello-tui/pkg/domain"
+	"github.com/giannimassi/trello-tui/pkg/importer"
+	"github.com/giannimassi/trello-tui/pkg/notify"
+)
+
 // State describes the interface required for the gui
 type State interface {
 	ViewState
@@ -9,12 +17,43 @@ type State interface {
 type ViewState interface {
 	HeaderState
 	ListsState
+	NotificationsState
+	MemberNotificationsState
+	ImportState
 }
 
 // HeaderState describes the interface required for the header component
 type HeaderState interface {
 	HeaderTitle() string
 	HeaderSubtitle() string
+	SyncStatus() SyncStatus
+	LastRefresh() time.Time // zero if the board was never loaded
+	BoardCounts() (lists, cards int)
+	RefreshInterval() time.Duration // zero if not known yet
+	UnreadNotifications() int
+}
+
+// SyncStatus describes the synchronization of the board with trello
+type SyncStatus int
+
+// Synchronization statuses
+const (
+	SyncIdle     SyncStatus = iota // the board is up to date as of the last refresh
+	SyncFetching                   // the board is being loaded
+	SyncRetrying                   // the board is being loaded after the last attempt failed
+	SyncOffline                    // the last attempt at loading the board failed
+)
+
+func (s SyncStatus) String() string {
+	switch s {
+	case SyncFetching:
+		return "fetching"
+	case SyncRetrying:
+		return "retrying"
+	case SyncOffline:
+		return "offline"
+	}
+	return "idle"
 }
 
 // ListsState describes the interface required for the ListContainer component
@@ -26,13 +65,44 @@ type ListsState interface {
 // SingleListState describes the interface required for the list component
 type SingleListState interface {
 	ListName(idx int) string
-	ListCardsIds(idx int) []int
+	ListCardsIds(idx int) []string
 	CardState
 }
 
 // CardState describes the interface required for the selected card component
 type CardState interface {
-	CardName(id int) string
-	CardLabelsStr(id int) string
-	Description(id int) string
+	CardName(id string) string
+	CardIdShort(id string) int
+	CardLabels(id string) []domain.CardLabel
+	Description(id string) string
+	CardDue(id string) (domain.Due, bool)
+	CardURL(id string) string
+	CardArchived(id string) bool
+	CardList(id string) (idx int, found bool)
+	CardChangedAt(id string) time.Time // zero if the card did not change recently
+}
+
+// NotificationsState describes the interface required for the notifications inbox component
+type NotificationsState interface {
+	Notifications() []notify.Event // newest first
+}
+
+// MemberNotificationsState describes the interface required for the trello notifications component
+type MemberNotificationsState interface {
+	MemberNotifications() []domain.Notification // newest first, nil until loaded
+	MemberNotificationsErr() error              // error of the last attempt at loading the notifications
+}
+
+// ImportState describes the interface required for the import preview component
+type ImportState interface {
+	Import() ImportStatus
+}
+
+// ImportStatus describes the import being previewed or run
+type ImportStatus struct {
+	Plan    *importer.Plan // nil while the file is being read or if it could not be read
+	Running bool
+	Runs    int   // number of times the plan was run, including the current run
+	Created int   // number of cards created by the last run of the plan
+	Err     error // error of the last attempt at reading the file or running the plan
 }
@@ -1,7 +1,11 @@
 package trello
 
 import (
+	"encoding/json"
 	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -20,6 +24,7 @@ var ErrBoardNotFound = errors.New("board not found")
 type Config struct {
 	User, Key, Token string
 	Timeout          time.Duration
+	BaseURL          string // base URL of the API, e.g. `https://api.trello.com/1`, used for testing
 }
 
 // Client makes requests via the trello API to get data for the current user
@@ -40,7 +45,15 @@ func NewClient(cfg *Config) *Client {
 // Init setups the client with the configuration provided, connection to trello's backend
 // is initialized on the first use
 func (t *Client) Init() error {
-	rr := trello.NewBearerTokenTransport(t.cfg.Key, &t.cfg.Token)
+	var rr http.RoundTripper = trello.NewBearerTokenTransport(t.cfg.Key, &t.cfg.Token)
+	if t.cfg.BaseURL != "" {
+		base, err := url.Parse(t.cfg.BaseURL)
+		if err != nil {
+			return errors.Wrapf(err, "invalid base URL %s", t.cfg.BaseURL)
+		}
+		rr = &baseURLTransport{base: base, delegate: rr}
+	}
+	rr = &retryTransport{delegate: rr}
 	httpClient := &http.Client{
 		Transport: rr,
 		Timeout:   t.cfg.Timeout,
@@ -58,6 +71,16 @@ func (t *Client) Init() error {
 
 // Board returns a domain.Board populated with the latest info about the specified board
 func (t *Client) Board(name string) (*domain.Board, error) {
+	return t.board(name, "open")
+}
+
+// BoardWithArchived returns the board with the provided name including its archived cards
+func (t *Client) BoardWithArchived(name string) (*domain.Board, error) {
+	return t.board(name, "all")
+}
+
+// board returns the board with the provided name and its cards matching the trello filter
+func (t *Client) board(name, cardsFilter string) (*domain.Board, error) {
 	t.l.Debug().Msg("Getting boards")
 	var board trello.Board
 
@@ -89,34 +112,430 @@ func (t *Client) Board(name string) (*domain.Board, error) {
 		return nil, errors.Wrapf(err, "while getting list for board %s", board.Name)
 	}
 
-	cards, err := board.Cards()
+	cards, err := t.boardCards(board.Id, cardsFilter)
 	if err != nil {
 		return nil, errors.Wrapf(err, "while getting cards for board %s", board.Name)
 	}
 
-	return domain.NewBoard(board.Name, board.Desc, listsByID(lists, cardsByListID(cards)), len(cards) == 0), nil
+	return domain.NewBoard(board.Id, board.Name, board.Desc, listsByID(lists, cardsByListID(cards, user.Id)), len(cards) == 0), nil
+}
+
+// Boards returns the names of the open boards of the user
+func (t *Client) Boards() ([]string, error) {
+	t.l.Debug().Msg("Getting board names")
+	user, err := t.client.Member(t.cfg.User)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get boards")
+	}
+	boards, err := user.Boards()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get boards")
+	}
+	names := make([]string, 0, len(boards))
+	for _, b := range boards {
+		if !b.Closed {
+			names = append(names, b.Name)
+		}
+	}
+	return names, nil
+}
+
+// card extends trello.Card with the fields not supported by the trello package
+type card struct {
+	trello.Card
+	DueComplete bool `json:"dueComplete"`
+	Members     []struct {
+		FullName string `json:"fullName"`
+	} `json:"members"`
+	Checklists []checklist `json:"checklists"`
+}
+
+// checklist is a trello checklist, positions are decoded as float since trello may return
+// fractional positions, which the trello package does not support for checklist items
+type checklist struct {
+	Name       string  `json:"name"`
+	Pos        float64 `json:"pos"`
+	CheckItems []struct {
+		Name  string  `json:"name"`
+		State string  `json:"state"`
+		Pos   float64 `json:"pos"`
+	} `json:"checkItems"`
+}
+
+// boardCards returns the cards matching the trello filter, e.g. `open` or `all`, of the board
+// with the corresponding id, including their members and checklists
+func (t *Client) boardCards(boardID, filter string) ([]card, error) {
+	body, err := t.client.Get("/boards/" + boardID + "/cards?members=true&checklists=all&filter=" + filter)
+	if err != nil {
+		return nil, err
+	}
+	var cards []card
+	if err := json.Unmarshal(body, &cards); err != nil {
+		return nil, errors.Wrap(err, "could not decode cards")
+	}
+	return cards, nil
+}
+
+// SetCardDue sets the due date of the card with the corresponding id, a nil due removes the due date
+func (t *Client) SetCardDue(cardID string, due *time.Time) error {
+	t.l.Debug().Str("card", cardID).Msg("Setting card due date")
+	value := ""
+	if due != nil {
+		value = due.UTC().Format(time.RFC3339)
+	}
+	if _, err := t.client.Put("/cards/"+cardID, url.Values{"due": {value}}); err != nil {
+		return errors.Wrapf(err, "while setting due date for card %s", cardID)
+	}
+	return nil
+}
+
+// SetCardDueComplete marks the due date of the card with the corresponding id as complete or not
+func (t *Client) SetCardDueComplete(cardID string, complete bool) error {
+	t.l.Debug().Str("card", cardID).Bool("complete", complete).Msg("Setting card due date completion")
+	if _, err := t.client.Put("/cards/"+cardID, url.Values{"dueComplete": {strconv.FormatBool(complete)}}); err != nil {
+		return errors.Wrapf(err, "while setting due date completion for card %s", cardID)
+	}
+	return nil
+}
+
+// CreateList creates a new list with the provided name and position in the board with the corresponding id
+func (t *Client) CreateList(boardID, name string, pos float64) (domain.List, error) {
+	t.l.Debug().Str("board", boardID).Str("name", name).Msg("Creating list")
+	body, err := t.client.Post("/lists", url.Values{
+		"idBoard": {boardID},
+		"name":    {name},
+		"pos":     {strconv.FormatFloat(pos, 'f', -1, 64)},
+	})
+	if err != nil {
+		return domain.List{}, errors.Wrapf(err, "while creating list %s", name)
+	}
+	var list trello.List
+	if err := json.Unmarshal(body, &list); err != nil {
+		return domain.List{}, errors.Wrap(err, "could not decode list")
+	}
+	return domain.NewList(list.Id, list.Name, float64(list.Pos), map[string]domain.Card{}), nil
+}
+
+// RenameList sets the name of the list with the corresponding id
+func (t *Client) RenameList(listID, name string) error {
+	t.l.Debug().Str("list", listID).Str("name", name).Msg("Renaming list")
+	if _, err := t.client.Put("/lists/"+listID, url.Values{"name": {name}}); err != nil {
+		return errors.Wrapf(err, "while renaming list %s", listID)
+	}
+	return nil
+}
+
+// ArchiveList archives the list with the corresponding id
+func (t *Client) ArchiveList(listID string) error {
+	t.l.Debug().Str("list", listID).Msg("Archiving list")
+	if _, err := t.client.Put("/lists/"+listID, url.Values{"closed": {"true"}}); err != nil {
+		return errors.Wrapf(err, "while archiving list %s", listID)
+	}
+	return nil
+}
+
+// MoveList sets the position of the list with the corresponding id
+func (t *Client) MoveList(listID string, pos float64) error {
+	t.l.Debug().Str("list", listID).Float64("pos", pos).Msg("Moving list")
+	if _, err := t.client.Put("/lists/"+listID, url.Values{"pos": {strconv.FormatFloat(pos, 'f', -1, 64)}}); err != nil {
+		return errors.Wrapf(err, "while moving list %s", listID)
+	}
+	return nil
+}
+
+// MoveCard moves the card with the corresponding id to the list with the corresponding id, at the provided position
+func (t *Client) MoveCard(cardID, listID string, pos float64) error {
+	t.l.Debug().Str("card", cardID).Str("list", listID).Float64("pos", pos).Msg("Moving card")
+	values := url.Values{
+		"idList": {listID},
+		"pos":    {strconv.FormatFloat(pos, 'f', -1, 64)},
+	}
+	if _, err := t.client.Put("/cards/"+cardID, values); err != nil {
+		return errors.Wrapf(err, "while moving card %s", cardID)
+	}
+	return nil
+}
+
+// AddComment adds a comment with the provided text to the card with the corresponding id
+func (t *Client) AddComment(cardID, text string) error {
+	t.l.Debug().Str("card", cardID).Msg("Adding comment")
+	if _, err := t.client.Post("/cards/"+cardID+"/actions/comments", url.Values{"text": {text}}); err != nil {
+		return errors.Wrapf(err, "while adding comment to card %s", cardID)
+	}
+	return nil
+}
+
+// label is a trello label of a board
+type label struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// member is a trello member of a board
+type member struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	FullName string `json:"fullName"`
+}
+
+// Labels returns the labels of the board with the corresponding id
+func (t *Client) Labels(boardID string) ([]domain.CardLabel, error) {
+	labels, err := t.boardLabels(boardID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]domain.CardLabel, len(labels))
+	for i, l := range labels {
+		result[i] = domain.CardLabel{Name: l.Name, Color: l.Color}
+	}
+	return result, nil
+}
+
+// boardLabels returns the trello labels of the board with the corresponding id
+func (t *Client) boardLabels(boardID string) ([]label, error) {
+	t.l.Debug().Str("board", boardID).Msg("Getting labels")
+	body, err := t.client.Get("/boards/" + boardID + "/labels")
+	if err != nil {
+		return nil, errors.Wrapf(err, "while getting labels for board %s", boardID)
+	}
+	var labels []label
+	if err := json.Unmarshal(body, &labels); err != nil {
+		return nil, errors.Wrap(err, "could not decode labels")
+	}
+	return labels, nil
+}
+
+// boardMembers returns the trello members of the board with the corresponding id
+func (t *Client) boardMembers(boardID string) ([]member, error) {
+	t.l.Debug().Str("board", boardID).Msg("Getting members")
+	body, err := t.client.Get("/boards/" + boardID + "/members")
+	if err != nil {
+		return nil, errors.Wrapf(err, "while getting members for board %s", boardID)
+	}
+	var members []member
+	if err := json.Unmarshal(body, &members); err != nil {
+		return nil, errors.Wrap(err, "could not decode members")
+	}
+	return members, nil
+}
+
+// CreateCard creates the card at the bottom of the list with the corresponding id, labels are
+// matched by name, or by colour if unnamed, and members by full name or username, the ones not
+// found on the board are logged and left out of the card returned
+func (t *Client) CreateCard(boardID, listID string, c domain.Card) (domain.Card, error) {
+	t.l.Debug().Str("list", listID).Str("name", c.Name).Msg("Creating card")
+	values := url.Values{
+		"idList": {listID},
+		"name":   {c.Name},
+		"desc":   {c.Description},
+		"pos":    {"bottom"},
+	}
+	if c.Due != nil {
+		values.Set("due", c.Due.Time.UTC().Format(time.RFC3339))
+		values.Set("dueComplete", strconv.FormatBool(c.Due.Complete))
+	}
+
+	var labels []domain.CardLabel
+	if len(c.Labels) > 0 {
+		boardLabels, err := t.boardLabels(boardID)
+		if err != nil {
+			return domain.Card{}, err
+		}
+		available := make([]domain.CardLabel, len(boardLabels))
+		for i, l := range boardLabels {
+			available[i] = domain.CardLabel{Name: l.Name, Color: l.Color}
+		}
+		var unknown []string
+		labels, unknown = domain.MatchLabels(domain.LabelNames(c.Labels), available)
+		if len(unknown) > 0 {
+			t.l.Warn().Strs("labels", unknown).Str("name", c.Name).Msg("Labels not found on the board, they are left out")
+		}
+		ids := make([]string, len(labels))
+		for i, l := range labels {
+			for _, bl := range boardLabels {
+				if bl.Name == l.Name && bl.Color == l.Color {
+					ids[i] = bl.ID
+					break
+				}
+			}
+		}
+		values.Set("idLabels", strings.Join(ids, ","))
+	}
+
+	var (
+		members  []string
+		assigned bool
+	)
+	if len(c.Members) > 0 {
+		boardMembers, err := t.boardMembers(boardID)
+		if err != nil {
+			return domain.Card{}, err
+		}
+		var ids, unknown []string
+		for _, name := range c.Members {
+			found := false
+			for _, m := range boardMembers {
+				if strings.EqualFold(m.FullName, name) || strings.EqualFold(m.Username, name) {
+					ids = append(ids, m.ID)
+					members = append(members, m.FullName)
+					assigned = assigned || m.ID == t.cfg.User || strings.EqualFold(m.Username, t.cfg.User)
+					found = true
+					break
+				}
+			}
+			if !found {
+				unknown = append(unknown, name)
+			}
+		}
+		if len(unknown) > 0 {
+			t.l.Warn().Strs("members", unknown).Str("name", c.Name).Msg("Members not found on the board, they are left out")
+		}
+		values.Set("idMembers", strings.Join(ids, ","))
+	}
+
+	body, err := t.client.Post("/cards", values)
+	if err != nil {
+		return domain.Card{}, errors.Wrapf(err, "while creating card %s", c.Name)
+	}
+	var created card
+	if err := json.Unmarshal(body, &created); err != nil {
+		return domain.Card{}, errors.Wrap(err, "could not decode card")
+	}
+	result := domain.NewCard(created.Id, created.IdShort, created.Name, created.Desc, created.Pos, labels, cardDue(created))
+	result.Members = members
+	result.Assigned = assigned
+	result.Watched = created.Subscribed
+	result.URL = created.ShortUrl
+	return result, nil
+}
+
+// notificationsLimit is the maximum number of notifications fetched
+const notificationsLimit = 50
+
+// notificationText holds the fields of trello notifications not supported by the trello package
+type notificationText struct {
+	Data struct {
+		Text string `json:"text"`
+	} `json:"data"`
 }
 
-func listsByID(trelloCards []trello.List, cards map[string]map[int]domain.Card) []domain.List {
+// Notifications returns the latest notifications of the user, newest first
+func (t *Client) Notifications() ([]domain.Notification, error) {
+	t.l.Debug().Msg("Getting notifications")
+	body, err := t.client.Get("/members/" + t.cfg.User + "/notifications?limit=" + strconv.Itoa(notificationsLimit))
+	if err != nil {
+		return nil, errors.Wrap(err, "while getting notifications")
+	}
+	var (
+		trelloNotifications []trello.Notification
+		texts               []notificationText
+	)
+	if err := json.Unmarshal(body, &trelloNotifications); err != nil {
+		return nil, errors.Wrap(err, "could not decode notifications")
+	}
+	if err := json.Unmarshal(body, &texts); err != nil {
+		return nil, errors.Wrap(err, "could not decode notifications")
+	}
+
+	notifications := make([]domain.Notification, len(trelloNotifications))
+	for i, n := range trelloNotifications {
+		date, _ := time.Parse(time.RFC3339, n.Date)
+		notifications[i] = domain.Notification{
+			ID:         n.Id,
+			Type:       n.Type,
+			Date:       date.Local(),
+			Unread:     n.Unread,
+			Creator:    n.MemberCreator.FullName,
+			BoardName:  n.Data.Board.Name,
+			CardID:     n.Data.Card.Id,
+			CardNumber: n.Data.Card.IdShort,
+			CardName:   n.Data.Card.Name,
+			Text:       texts[i].Data.Text,
+		}
+	}
+	return notifications, nil
+}
+
+// MarkNotificationRead marks the notification with the corresponding id as read
+func (t *Client) MarkNotificationRead(notificationID string) error {
+	t.l.Debug().Str("notification", notificationID).Msg("Marking notification as read")
+	if _, err := t.client.Put("/notifications/"+notificationID+"/unread", url.Values{"value": {"false"}}); err != nil {
+		return errors.Wrapf(err, "while marking notification %s as read", notificationID)
+	}
+	return nil
+}
+
+func listsByID(trelloCards []trello.List, cards map[string]map[string]domain.Card) []domain.List {
 	lists := make([]domain.List, 0, len(trelloCards))
 	for _, c := range trelloCards {
-		lists = append(lists, domain.NewList(c.Id, c.Name, cards[c.Id]))
+		lists = append(lists, domain.NewList(c.Id, c.Name, float64(c.Pos), cards[c.Id]))
 	}
 	return lists
 }
 
-func cardsByListID(trelloCards []trello.Card) map[string]map[int]domain.Card {
-	cards := make(map[string]map[int]domain.Card)
+// cardsByListID returns the cards by id grouped by the id of the list containing them, memberID
+// is the id of the user, used for telling which cards are assigned to the user
+func cardsByListID(trelloCards []card, memberID string) map[string]map[string]domain.Card {
+	cards := make(map[string]map[string]domain.Card)
 	for _, c := range trelloCards {
 		if cards[c.IdList] == nil {
-			cards[c.IdList] = make(map[int]domain.Card)
+			cards[c.IdList] = make(map[string]domain.Card)
 		}
 		labels := make([]domain.CardLabel, len(c.Labels))
 		for i, lbl := range c.Labels {
 			labels[i] = domain.CardLabel{Name: lbl.Name, Color: lbl.Color}
 		}
 
-		cards[c.IdList][c.IdShort] = domain.NewCard(c.Id, c.Name, c.Desc, c.Pos, labels)
+		card := domain.NewCard(c.Id, c.IdShort, c.Name, c.Desc, c.Pos, labels, cardDue(c))
+		card.Assigned = contains(c.IdMembers, memberID)
+		card.Watched = c.Subscribed
+		card.Comments = c.Badges.Comments
+		for _, m := range c.Members {
+			card.Members = append(card.Members, m.FullName)
+		}
+		card.Checklists = checklists(c.Checklists)
+		card.URL = c.ShortUrl
+		card.Archived = c.Closed
+		cards[c.IdList][c.Id] = card
 	}
 	return cards
 }
+
+// checklists returns the checklists and their items sorted by position
+func checklists(trelloChecklists []checklist) []domain.Checklist {
+	if len(trelloChecklists) == 0 {
+		return nil
+	}
+	sort.SliceStable(trelloChecklists, func(i, j int) bool { return trelloChecklists[i].Pos < trelloChecklists[j].Pos })
+	result := make([]domain.Checklist, len(trelloChecklists))
+	for i, cl := range trelloChecklists {
+		items := cl.CheckItems
+		sort.SliceStable(items, func(i, j int) bool { return items[i].Pos < items[j].Pos })
+		result[i] = domain.Checklist{Name: cl.Name, Items: make([]domain.ChecklistItem, len(items))}
+		for j, item := range items {
+			result[i].Items[j] = domain.ChecklistItem{Name: item.Name, Complete: item.State == "complete"}
+		}
+	}
+	return result
+}
+
+func cardDue(c card) *domain.Due {
+	if c.Due == "" {
+		return nil
+	}
+	due, err := time.Parse(time.RFC3339, c.Due)
+	if err != nil {
+		return nil
+	}
+	return &domain.Due{Time: due.Local(), Complete: c.DueComplete}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,385 @@
+package trello
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/giannimassi/trello-tui/pkg/domain"
+	"github.com/giannimassi/trello-tui/pkg/trello/fake"
+)
+
+func init() {
+	zerolog.SetGlobalLevel(zerolog.Disabled)
+}
+
+// newDemoClient returns a client initialized against a fake server seeded with the demo board
+func newDemoClient(t *testing.T) (*Client, *fake.Server) {
+	t.Helper()
+	server := fake.NewDemoServer()
+	client := NewClient(&Config{
+		User:    fake.DemoUser,
+		Key:     "key",
+		Token:   "token",
+		Timeout: 5 * time.Second,
+		BaseURL: server.APIURL(),
+	})
+	if err := client.Init(); err != nil {
+		server.Close()
+		t.Fatal(err)
+	}
+	return client, server
+}
+
+// demoBoard loads the demo board
+func demoBoard(t *testing.T, client *Client) *domain.Board {
+	t.Helper()
+	b, err := client.Board(fake.DemoBoard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// listIndex returns the index of the list with the provided name
+func listIndex(t *testing.T, b *domain.Board, name string) int {
+	t.Helper()
+	for i, l := range b.Lists {
+		if l.Name == name {
+			return i
+		}
+	}
+	t.Fatalf("list %q not found", name)
+	return -1
+}
+
+// cardNamed returns the card with the provided name and the index of the list containing it
+func cardNamed(t *testing.T, b *domain.Board, name string) (domain.Card, int) {
+	t.Helper()
+	for i, l := range b.Lists {
+		for _, c := range l.CardsByID {
+			if c.Name == name {
+				return c, i
+			}
+		}
+	}
+	t.Fatalf("card %q not found", name)
+	return domain.Card{}, -1
+}
+
+// recordingTransport records the URL of the requests and responds with the provided statuses,
+// the last one being repeated
+type recordingTransport struct {
+	statuses []int
+	urls     []string
+	bodies   []string
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.urls = append(t.urls, req.URL.String())
+	if req.Body != nil {
+		body := make([]byte, 64)
+		n, _ := req.Body.Read(body)
+		t.bodies = append(t.bodies, string(body[:n]))
+	}
+	status := t.statuses[len(t.statuses)-1]
+	if len(t.urls) <= len(t.statuses) {
+		status = t.statuses[len(t.urls)-1]
+	}
+	return &http.Response{StatusCode: status, Body: http.NoBody, Header: http.Header{}, Request: req}, nil
+}
+
+func TestBaseURLTransport(t *testing.T) {
+	tests := []struct {
+		base, request, want string
+	}{
+		{"http://127.0.0.1:8080/1", "https://api.trello.com/1/boards/abc?key=k", "http://127.0.0.1:8080/1/boards/abc?key=k"},
+		{"http://127.0.0.1:8080/1/", "https://api.trello.com/1/members/me", "http://127.0.0.1:8080/1/members/me"},
+		{"https://proxy.example.com/trello/api", "https://api.trello.com/1/cards/c1", "https://proxy.example.com/trello/api/cards/c1"},
+		{"http://localhost", "https://api.trello.com/1/lists", "http://localhost/lists"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.base, func(t *testing.T) {
+			base, err := url.Parse(tt.base)
+			if err != nil {
+				t.Fatal(err)
+			}
+			recorder := &recordingTransport{statuses: []int{http.StatusOK}}
+			req, err := http.NewRequest(http.MethodGet, tt.request, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := (&baseURLTransport{base: base, delegate: recorder}).RoundTrip(req); err != nil {
+				t.Fatal(err)
+			}
+			if recorder.urls[0] != tt.want {
+				t.Errorf("request sent to %s, want %s", recorder.urls[0], tt.want)
+			}
+			if req.URL.String() != tt.request {
+				t.Errorf("original request modified: %s", req.URL)
+			}
+		})
+	}
+}
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		statuses []int
+		want     int // status returned
+		attempts int
+	}{
+		{name: "success", method: http.MethodGet, statuses: []int{200}, want: 200, attempts: 1},
+		{name: "rate limited", method: http.MethodPut, statuses: []int{429, 429, 200}, want: 200, attempts: 3},
+		{name: "rate limited too long", method: http.MethodGet, statuses: []int{429}, want: 429, attempts: maxRetries + 1},
+		{name: "server error on read", method: http.MethodGet, statuses: []int{500, 200}, want: 200, attempts: 2},
+		{name: "server error on write", method: http.MethodPost, statuses: []int{500, 200}, want: 500, attempts: 1},
+		{name: "client error", method: http.MethodGet, statuses: []int{404, 200}, want: 404, attempts: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &recordingTransport{statuses: tt.statuses}
+			var body io.Reader
+			if tt.method != http.MethodGet {
+				body = strings.NewReader("name=Doing")
+			}
+			req, err := http.NewRequest(tt.method, "http://localhost/1/cards/c1", body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := (&retryTransport{delegate: recorder}).RoundTrip(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.want || len(recorder.urls) != tt.attempts {
+				t.Errorf("status %d after %d attempts, want %d after %d", resp.StatusCode, len(recorder.urls), tt.want, tt.attempts)
+			}
+			for i, b := range recorder.bodies {
+				if b != "name=Doing" {
+					t.Errorf("attempt %d sent body %q", i+1, b)
+				}
+			}
+		})
+	}
+}
+
+func TestClientBoard(t *testing.T) {
+	client, server := newDemoClient(t)
+	defer server.Close()
+
+	boards, err := client.Boards()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(boards) != 2 || boards[0] != fake.DemoBoard {
+		t.Errorf("boards = %v, want the demo and the personal boards", boards)
+	}
+
+	b := demoBoard(t, client)
+	var cards int
+	for _, l := range b.Lists {
+		cards += len(l.CartIds)
+	}
+	if len(b.Lists) != 5 || cards != 11 {
+		t.Errorf("board has %d lists and %d cards, want 5 and 11", len(b.Lists), cards)
+	}
+	c, _ := cardNamed(t, b, "Fix login redirect loop")
+	if !c.Assigned || c.Due == nil || c.Comments != 1 || len(c.Checklists) != 1 || c.URL == "" {
+		t.Errorf("card details not loaded: %+v", c)
+	}
+
+	archived, err := client.BoardWithArchived(fake.DemoBoard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c, _ := cardNamed(t, archived, "Sync with the legacy API"); !c.Archived {
+		t.Errorf("archived card not marked as archived")
+	}
+}
+
+func TestClientRetries(t *testing.T) {
+	client, server := newDemoClient(t)
+	defer server.Close()
+
+	server.FailNext(maxRetries, http.StatusInternalServerError)
+	if _, err := client.Board(fake.DemoBoard); err != nil {
+		t.Errorf("board not loaded after %d server errors: %v", maxRetries, err)
+	}
+
+	server.FailNext(maxRetries+1, http.StatusInternalServerError)
+	if _, err := client.Board(fake.DemoBoard); err == nil {
+		t.Errorf("board loaded after %d server errors", maxRetries+1)
+	}
+
+	b := demoBoard(t, client)
+	requests := server.Requests()
+	server.FailNext(1, http.StatusInternalServerError)
+	if _, err := client.CreateList(b.ID, "Blocked", b.ListPos(len(b.Lists))); err == nil {
+		t.Errorf("list created after a server error")
+	}
+	if server.Requests() != requests+1 {
+		t.Errorf("list creation attempted %d times, want once", server.Requests()-requests)
+	}
+
+	server.FailNext(1, http.StatusTooManyRequests)
+	if _, err := client.CreateList(b.ID, "Blocked", b.ListPos(len(b.Lists))); err != nil {
+		t.Errorf("list not created after being rate limited: %v", err)
+	}
+	if lists := len(demoBoard(t, client).Lists); lists != len(b.Lists)+1 {
+		t.Errorf("board has %d lists, want %d", lists, len(b.Lists)+1)
+	}
+
+	// the requests exceeding the limit are rejected until the window is over
+	server.SetRateLimit(1, time.Minute)
+	status := func() (int, string) {
+		t.Helper()
+		resp, err := http.Get(server.APIURL() + "/members/me?key=key&token=token")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode, resp.Header.Get("Retry-After")
+	}
+	if code, after := status(); code != http.StatusOK || after != "" {
+		t.Errorf("first request = %d with Retry-After %q, want 200 without", code, after)
+	}
+	if code, after := status(); code != http.StatusTooManyRequests || after != "60" && after != "59" {
+		t.Errorf("request exceeding the limit = %d with Retry-After %q, want 429 after 60 seconds", code, after)
+	}
+
+	// and retried once the window is over
+	server.SetRateLimit(2, retryWait/2)
+	defer server.SetRateLimit(0, 0)
+	if _, err := client.Board(fake.DemoBoard); err != nil {
+		t.Errorf("board not loaded while rate limited: %v", err)
+	}
+}
+
+func TestFakeMemberInitials(t *testing.T) {
+	server := fake.NewDemoServer()
+	defer server.Close()
+	server.AddMember("elodie", "Élodie Ørsted")
+	resp, err := http.Get(server.APIURL() + "/members/elodie?key=key&token=token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var m fake.Member
+	if err := json.NewDecoder(resp.Body).Decode(&m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Initials != "ÉØ" {
+		t.Errorf("initials = %q, want ÉØ", m.Initials)
+	}
+}
+
+func TestClientCardUpdates(t *testing.T) {
+	client, server := newDemoClient(t)
+	defer server.Close()
+	b := demoBoard(t, client)
+	card, _ := cardNamed(t, b, "Export board as CSV")
+
+	due := time.Date(2030, time.January, 2, 17, 0, 0, 0, time.UTC)
+	if err := client.SetCardDue(card.ID, &due); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.SetCardDueComplete(card.ID, true); err != nil {
+		t.Fatal(err)
+	}
+	if c, _ := cardNamed(t, demoBoard(t, client), card.Name); c.Due == nil || !c.Due.Time.Equal(due) || !c.Due.Complete {
+		t.Errorf("due = %+v, want %s complete", c.Due, due)
+	}
+	if err := client.SetCardDue(card.ID, nil); err != nil {
+		t.Fatal(err)
+	}
+	if c, _ := cardNamed(t, demoBoard(t, client), card.Name); c.Due != nil {
+		t.Errorf("due = %+v, want none", c.Due)
+	}
+
+	done := b.Lists[listIndex(t, b, "Done")]
+	if err := client.MoveCard(card.ID, done.ID, done.CardPos(0)); err != nil {
+		t.Fatal(err)
+	}
+	b = demoBoard(t, client)
+	doneIdx := listIndex(t, b, "Done")
+	if _, list := cardNamed(t, b, card.Name); list != doneIdx || b.Lists[doneIdx].CartIds[0] != card.ID {
+		t.Errorf("card not moved to the top of Done")
+	}
+}
+
+func TestClientListUpdates(t *testing.T) {
+	client, server := newDemoClient(t)
+	defer server.Close()
+	b := demoBoard(t, client)
+
+	l, err := client.CreateList(b.ID, "Blocked", b.ListPos(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b = demoBoard(t, client)
+	if idx := listIndex(t, b, "Blocked"); idx != 1 || b.Lists[idx].ID != l.ID {
+		t.Errorf("list created at %d, want 1", idx)
+	}
+
+	if err := client.RenameList(l.ID, "On hold"); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.MoveList(l.ID, b.ListPos(len(b.Lists))); err != nil {
+		t.Fatal(err)
+	}
+	b = demoBoard(t, client)
+	if idx := listIndex(t, b, "On hold"); idx != len(b.Lists)-1 {
+		t.Errorf("list moved to %d, want %d", idx, len(b.Lists)-1)
+	}
+
+	if err := client.ArchiveList(l.ID); err != nil {
+		t.Fatal(err)
+	}
+	for _, list := range demoBoard(t, client).Lists {
+		if list.ID == l.ID {
+			t.Errorf("archived list still in the board")
+		}
+	}
+}
+
+func TestClientCreateCard(t *testing.T) {
+	client, server := newDemoClient(t)
+	defer server.Close()
+	b := demoBoard(t, client)
+	todo := b.Lists[listIndex(t, b, "To Do")]
+
+	due := domain.Due{Time: time.Date(2030, time.March, 1, 9, 0, 0, 0, time.UTC)}
+	c := domain.NewCard("", 0, "Write the changelog", "For the next release.", 0,
+		[]domain.CardLabel{{Name: "chore"}, {Name: "unknown"}}, &due)
+	c.Members = []string{"Jane Doe", fake.DemoUser, "nobody"}
+	created, err := client.CreateCard(b.ID, todo.ID, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.ID == "" || created.IdShort == 0 || created.URL == "" || !created.Assigned {
+		t.Errorf("created card = %+v", created)
+	}
+	// the labels and members not found on the board are left out of the card returned
+	if len(created.Labels) != 1 || len(created.Members) != 2 {
+		t.Errorf("created labels, members = %v, %v, want only the ones of the board", created.Labels, created.Members)
+	}
+
+	b = demoBoard(t, client)
+	loaded, list := cardNamed(t, b, c.Name)
+	if list != listIndex(t, b, "To Do") || b.Lists[list].CartIds[len(b.Lists[list].CartIds)-1] != loaded.ID {
+		t.Errorf("card not created at the bottom of To Do")
+	}
+	if names := domain.LabelNames(loaded.Labels); len(names) != 1 || names[0] != "chore" {
+		t.Errorf("labels = %v, want [chore]", names)
+	}
+	if len(loaded.Members) != 2 || loaded.Description != c.Description || loaded.Due == nil || !loaded.Due.Time.Equal(due.Time) {
+		t.Errorf("card details not saved: %+v", loaded)
+	}
+}
@@ -0,0 +1,304 @@
+package fake
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/giannimassi/trello-tui/pkg/domain"
+)
+
+// Member is a trello member as returned by the API
+type Member struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	FullName string `json:"fullName"`
+	Initials string `json:"initials"`
+}
+
+// Board is a trello board as returned by the API
+type Board struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Desc      string   `json:"desc"`
+	Closed    bool     `json:"closed"`
+	URL       string   `json:"url"`
+	ShortURL  string   `json:"shortUrl"`
+	IDMembers []string `json:"idMembers"`
+}
+
+// List is a trello list as returned by the API
+type List struct {
+	ID      string  `json:"id"`
+	Name    string  `json:"name"`
+	Closed  bool    `json:"closed"`
+	IDBoard string  `json:"idBoard"`
+	Pos     float64 `json:"pos"`
+}
+
+// Label is a trello label as returned by the API
+type Label struct {
+	ID      string `json:"id"`
+	IDBoard string `json:"idBoard"`
+	Name    string `json:"name"`
+	Color   string `json:"color"`
+}
+
+// Badges summarizes the content of a card
+type Badges struct {
+	Comments int `json:"comments"`
+}
+
+// Card is a trello card as returned by the API, labels are embedded as done by trello
+type Card struct {
+	ID               string     `json:"id"`
+	IDShort          int        `json:"idShort"`
+	Name             string     `json:"name"`
+	Desc             string     `json:"desc"`
+	Closed           bool       `json:"closed"`
+	IDBoard          string     `json:"idBoard"`
+	IDList           string     `json:"idList"`
+	Pos              float64    `json:"pos"`
+	Due              *time.Time `json:"due"`
+	DueComplete      bool       `json:"dueComplete"`
+	IDMembers        []string   `json:"idMembers"`
+	IDLabels         []string   `json:"idLabels"`
+	Labels           []Label    `json:"labels"`
+	Subscribed       bool       `json:"subscribed"`
+	Badges           Badges     `json:"badges"`
+	ShortLink        string     `json:"shortLink"`
+	ShortURL         string     `json:"shortUrl"`
+	URL              string     `json:"url"`
+	DateLastActivity time.Time  `json:"dateLastActivity"`
+
+	// embedded when requested with the members and checklists parameters
+	Members    []Member     `json:"members,omitempty"`
+	Checklists []*Checklist `json:"checklists,omitempty"`
+}
+
+// Checklist is a trello checklist as returned by the API
+type Checklist struct {
+	ID         string      `json:"id"`
+	IDCard     string      `json:"idCard"`
+	Name       string      `json:"name"`
+	Pos        float64     `json:"pos"`
+	CheckItems []CheckItem `json:"checkItems"`
+}
+
+// CheckItem is an item of a trello checklist, its state is either `complete` or `incomplete`
+type CheckItem struct {
+	ID    string  `json:"id"`
+	Name  string  `json:"name"`
+	State string  `json:"state"`
+	Pos   float64 `json:"pos"`
+}
+
+// ActionData holds the objects concerned by an action or a notification
+type ActionData struct {
+	Text  string `json:"text,omitempty"`
+	Board *Ref   `json:"board,omitempty"`
+	List  *Ref   `json:"list,omitempty"`
+	Card  *Ref   `json:"card,omitempty"`
+}
+
+// Ref is a reference to a board, list or card within actions and notifications
+type Ref struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	ShortLink string `json:"shortLink,omitempty"`
+	IDShort   int    `json:"idShort,omitempty"`
+}
+
+// Action is a trello action as returned by the API, only comments are supported
+type Action struct {
+	ID              string     `json:"id"`
+	Type            string     `json:"type"`
+	Date            time.Time  `json:"date"`
+	IDMemberCreator string     `json:"idMemberCreator"`
+	MemberCreator   Member     `json:"memberCreator"`
+	Data            ActionData `json:"data"`
+}
+
+// Notification is a trello notification as returned by the API
+type Notification struct {
+	ID              string     `json:"id"`
+	IDMember        string     `json:"-"`
+	Type            string     `json:"type"`
+	Date            time.Time  `json:"date"`
+	Unread          bool       `json:"unread"`
+	IDMemberCreator string     `json:"idMemberCreator"`
+	MemberCreator   Member     `json:"memberCreator"`
+	Data            ActionData `json:"data"`
+}
+
+// data is the in-memory content of the fake trello backend
+type data struct {
+	lastID        int
+	members       []*Member
+	boards        []*Board
+	lists         []*List
+	labels        []*Label
+	cards         []*Card
+	actions       []*Action
+	notifications []*Notification
+	checklists    []*Checklist
+}
+
+// newID returns a new identifier in the same format used by trello
+func (d *data) newID() string {
+	d.lastID++
+	return fmt.Sprintf("%024x", d.lastID)
+}
+
+func (d *data) member(idOrUsername string) *Member {
+	for _, m := range d.members {
+		if m.ID == idOrUsername || m.Username == idOrUsername {
+			return m
+		}
+	}
+	return nil
+}
+
+func (d *data) board(id string) *Board {
+	for _, b := range d.boards {
+		if b.ID == id {
+			return b
+		}
+	}
+	return nil
+}
+
+func (d *data) list(id string) *List {
+	for _, l := range d.lists {
+		if l.ID == id {
+			return l
+		}
+	}
+	return nil
+}
+
+func (d *data) card(id string) *Card {
+	for _, c := range d.cards {
+		if c.ID == id || c.ShortLink == id {
+			return c
+		}
+	}
+	return nil
+}
+
+func (d *data) label(id string) *Label {
+	for _, l := range d.labels {
+		if l.ID == id {
+			return l
+		}
+	}
+	return nil
+}
+
+func (d *data) notification(id string) *Notification {
+	for _, n := range d.notifications {
+		if n.ID == id {
+			return n
+		}
+	}
+	return nil
+}
+
+// memberBoards returns the open boards of the member
+func (d *data) memberBoards(memberID string) []*Board {
+	boards := []*Board{}
+	for _, b := range d.boards {
+		if !b.Closed && contains(b.IDMembers, memberID) {
+			boards = append(boards, b)
+		}
+	}
+	return boards
+}
+
+// boardLists returns the open lists of the board, sorted by position
+func (d *data) boardLists(boardID string) []*List {
+	lists := []*List{}
+	for _, l := range d.lists {
+		if l.IDBoard == boardID && !l.Closed {
+			lists = append(lists, l)
+		}
+	}
+	sort.SliceStable(lists, func(i, j int) bool { return lists[i].Pos < lists[j].Pos })
+	return lists
+}
+
+// cardsWhere returns the open cards matching the filter, sorted by position
+func (d *data) cardsWhere(filter func(c *Card) bool) []*Card {
+	return d.allCardsWhere(func(c *Card) bool { return !c.Closed && filter(c) })
+}
+
+// allCardsWhere returns the cards, including the archived ones, for which filter returns true,
+// sorted by position
+func (d *data) allCardsWhere(filter func(c *Card) bool) []*Card {
+	cards := []*Card{}
+	for _, c := range d.cards {
+		if filter(c) {
+			cards = append(cards, c)
+		}
+	}
+	sort.SliceStable(cards, func(i, j int) bool { return cards[i].Pos < cards[j].Pos })
+	return cards
+}
+
+// lastPos returns the position after the last list of the board or card of the list
+func (d *data) lastPos(boardID, listID string) float64 {
+	var pos float64
+	if listID == "" {
+		for _, l := range d.boardLists(boardID) {
+			pos = l.Pos
+		}
+	} else {
+		for _, c := range d.cardsWhere(func(c *Card) bool { return c.IDList == listID }) {
+			pos = c.Pos
+		}
+	}
+	return pos + domain.PosStep
+}
+
+// firstPos returns the position before the first list of the board or card of the list
+func (d *data) firstPos(boardID, listID string) float64 {
+	if listID == "" {
+		if lists := d.boardLists(boardID); len(lists) > 0 {
+			return lists[0].Pos / 2
+		}
+	} else if cards := d.cardsWhere(func(c *Card) bool { return c.IDList == listID }); len(cards) > 0 {
+		return cards[0].Pos / 2
+	}
+	return domain.PosStep
+}
+
+// cardChecklists returns the checklists of the card with the corresponding id
+func (d *data) cardChecklists(cardID string) []*Checklist {
+	checklists := []*Checklist{}
+	for _, cl := range d.checklists {
+		if cl.IDCard == cardID {
+			checklists = append(checklists, cl)
+		}
+	}
+	return checklists
+}
+
+// nextIDShort returns the number of the next card created in the board
+func (d *data) nextIDShort(boardID string) int {
+	var idShort int
+	for _, c := range d.cards {
+		if c.IDBoard == boardID && c.IDShort > idShort {
+			idShort = c.IDShort
+		}
+	}
+	return idShort + 1
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
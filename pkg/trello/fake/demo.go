@@ -0,0 +1,146 @@
+package fake
+
+import (
+	"time"
+)
+
+const (
+	// DemoUser is the username of the member of the demo server
+	DemoUser = "demo"
+	// DemoBoard is the name of the main board of the demo server
+	DemoBoard = "Demo"
+)
+
+// demoCard describes a card of the demo board
+type demoCard struct {
+	list     int
+	name     string
+	desc     string
+	labels   []int
+	due      time.Duration // due date relative to the seeding time, zero if none
+	assigned bool
+	watched  bool
+	comments []string
+	archived bool
+}
+
+var demoCards = []demoCard{
+	{list: 0, name: "Dark mode for the settings page", labels: []int{0}},
+	{list: 0, name: "Export board as CSV", desc: "Useful for weekly reports.", labels: []int{0, 3}},
+	{list: 0, name: "Investigate slow startup on Windows", labels: []int{1}},
+	{list: 1, name: "Fix login redirect loop", desc: "Happens when the session expires while a modal is open.", labels: []int{1, 2}, due: 26 * time.Hour, assigned: true, comments: []string{"I can reproduce it on staging."}},
+	{list: 1, name: "Update dependencies", labels: []int{3}, due: 5 * 24 * time.Hour},
+	{list: 1, name: "Write release notes", due: 3 * time.Hour, watched: true},
+	{list: 2, name: "Keyboard shortcuts overlay", desc: "Press `?` to list the available keys.", labels: []int{0}, assigned: true},
+	{list: 2, name: "Retry failed requests with backoff", labels: []int{3}, due: -2 * time.Hour, watched: true},
+	{list: 3, name: "Drag and drop between lists", labels: []int{0}, assigned: true, comments: []string{"Looks good, a couple of nits.", "Nits addressed."}},
+	{list: 4, name: "Board switcher", labels: []int{0}},
+	{list: 4, name: "Offline mode", labels: []int{3}, due: -48 * time.Hour},
+	{list: 4, name: "Sync with the legacy API", labels: []int{3}, archived: true},
+}
+
+// NewDemoServer starts and returns a fake trello server seeded with a demo board, accessible as
+// DemoUser with any key and token
+func NewDemoServer() *Server {
+	s := NewServer()
+	now := time.Now()
+	me := s.AddMember(DemoUser, "Demo User")
+	jane := s.AddMember("jane", "Jane Doe")
+
+	board := s.AddBoard(DemoBoard, "A demo board served by a fake trello server, changes are lost on exit.", me, jane)
+	var lists []string
+	for _, name := range []string{"Backlog", "To Do", "In Progress", "Review", "Done"} {
+		lists = append(lists, s.AddList(board, name))
+	}
+	var labels []string
+	for _, l := range []struct{ name, color string }{{"feature", "green"}, {"bug", "red"}, {"urgent", "orange"}, {"chore", "blue"}} {
+		labels = append(labels, s.AddLabel(board, l.name, l.color))
+	}
+
+	var assigned []string
+	for _, c := range demoCards {
+		card := Card{Name: c.name, Desc: c.desc, IDMembers: []string{jane}, Subscribed: c.watched, Closed: c.archived}
+		for _, idx := range c.labels {
+			card.IDLabels = append(card.IDLabels, labels[idx])
+		}
+		if c.due != 0 {
+			due := now.Add(c.due).Truncate(time.Hour)
+			card.Due = &due
+			card.DueComplete = c.list == len(lists)-1
+		}
+		if c.assigned {
+			card.IDMembers = append(card.IDMembers, me)
+		}
+		id := s.AddCard(lists[c.list], card)
+		for _, text := range c.comments {
+			s.AddComment(id, jane, text)
+		}
+		if c.assigned {
+			assigned = append(assigned, id)
+		}
+	}
+
+	s.AddChecklist(assigned[0], "Before merging", "[x] Reproduce on staging", "Add a regression test", "Update the changelog")
+
+	s.AddNotification(me, "mentionedOnCard", jane, assigned[0], "@demo could you have a look before the release?")
+	s.AddNotification(me, "addedToCard", jane, assigned[1], "")
+
+	personal := s.AddBoard("Personal", "", me)
+	for _, name := range []string{"Ideas", "Doing", "Done"} {
+		s.AddList(personal, name)
+	}
+	return s
+}
+
+// Simulate modifies the cards assigned to the first member at the provided interval, as if
+// another member was working on them, until stop is called
+func (s *Server) Simulate(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for step := 0; ; step++ {
+			select {
+			case <-done:
+				return
+			case <-t.C:
+				s.simulateStep(step)
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// simulateStep comments, reschedules or moves one of the cards assigned to the first member
+func (s *Server) simulateStep(step int) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if len(s.data.members) < 2 {
+		return
+	}
+	me, other := s.data.members[0], s.data.members[1]
+	cards := s.data.cardsWhere(func(c *Card) bool { return contains(c.IDMembers, me.ID) })
+	if len(cards) == 0 {
+		return
+	}
+	c := cards[step%len(cards)]
+	now := time.Now()
+	switch step % 3 {
+	case 0:
+		s.data.comment(c.ID, other.ID, "Any update on this?", now)
+	case 1:
+		due := now.Add(24 * time.Hour).Truncate(time.Hour)
+		c.Due = &due
+		c.DateLastActivity = now
+	default:
+		lists := s.data.boardLists(c.IDBoard)
+		for i, l := range lists {
+			if l.ID == c.IDList && i+1 < len(lists) {
+				c.IDList = lists[i+1].ID
+				c.Pos = s.data.lastPos(c.IDBoard, c.IDList)
+				c.DateLastActivity = now
+				break
+			}
+		}
+	}
+}
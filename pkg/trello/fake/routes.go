@@ -0,0 +1,377 @@
+package fake
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// handler handles a request for the resource with the provided id, the data lock is held
+type handler func(d *data, id string, form url.Values) (v interface{}, status int)
+
+// routes maps the method and path of the requests, where the id is replaced by `*`, to their handler
+var routes = map[string]handler{
+	"GET members/*":                 getMember,
+	"GET members/*/boards":          getMemberBoards,
+	"GET members/*/notifications":   getMemberNotifications,
+	"PUT notifications/*/unread":    putNotificationUnread,
+	"GET boards/*":                  getBoard,
+	"GET boards/*/lists":            getBoardLists,
+	"GET boards/*/cards":            getBoardCards,
+	"GET boards/*/labels":           getBoardLabels,
+	"GET boards/*/members":          getBoardMembers,
+	"GET lists/*":                   getList,
+	"GET lists/*/cards":             getListCards,
+	"POST lists":                    postList,
+	"PUT lists/*":                   putList,
+	"GET cards/*":                   getCard,
+	"POST cards":                    postCard,
+	"PUT cards/*":                   putCard,
+	"GET cards/*/actions":           getCardActions,
+	"POST cards/*/actions/comments": postCardComment,
+}
+
+// route dispatches the request to the handler matching its method and path
+func (s *Server) route(w http.ResponseWriter, r *http.Request, path []string) {
+	var id string
+	if len(path) > 1 {
+		id = path[1]
+		path[1] = "*"
+	}
+	h, found := routes[r.Method+" "+strings.Join(path, "/")]
+	if !found {
+		http.Error(w, "Cannot "+r.Method+" "+r.URL.Path, http.StatusNotFound)
+		return
+	}
+	v, status := h(&s.data, id, r.Form)
+	switch status {
+	case http.StatusOK:
+		writeJSON(w, v)
+	case http.StatusNotFound:
+		http.Error(w, "The requested resource was not found.", status)
+	default:
+		http.Error(w, "invalid value", status)
+	}
+}
+
+// memberOrMe returns the member with the provided id or username, `me` is the first member
+func (d *data) memberOrMe(id string) *Member {
+	if id == "me" && len(d.members) > 0 {
+		return d.members[0]
+	}
+	return d.member(id)
+}
+
+func getMember(d *data, id string, _ url.Values) (interface{}, int) {
+	m := d.memberOrMe(id)
+	if m == nil {
+		return nil, http.StatusNotFound
+	}
+	return m, http.StatusOK
+}
+
+func getMemberBoards(d *data, id string, _ url.Values) (interface{}, int) {
+	m := d.memberOrMe(id)
+	if m == nil {
+		return nil, http.StatusNotFound
+	}
+	return d.memberBoards(m.ID), http.StatusOK
+}
+
+func getMemberNotifications(d *data, id string, form url.Values) (interface{}, int) {
+	m := d.memberOrMe(id)
+	if m == nil {
+		return nil, http.StatusNotFound
+	}
+	notifications := []*Notification{}
+	for _, n := range d.notifications {
+		if n.IDMember == m.ID {
+			notifications = append(notifications, n)
+		}
+	}
+	sort.SliceStable(notifications, func(i, j int) bool { return notifications[i].Date.After(notifications[j].Date) })
+	if limit, err := strconv.Atoi(form.Get("limit")); err == nil && limit < len(notifications) {
+		notifications = notifications[:limit]
+	}
+	return notifications, http.StatusOK
+}
+
+func putNotificationUnread(d *data, id string, form url.Values) (interface{}, int) {
+	n := d.notification(id)
+	if n == nil {
+		return nil, http.StatusNotFound
+	}
+	unread, err := strconv.ParseBool(form.Get("value"))
+	if err != nil {
+		return nil, http.StatusBadRequest
+	}
+	n.Unread = unread
+	return n, http.StatusOK
+}
+
+func getBoard(d *data, id string, _ url.Values) (interface{}, int) {
+	b := d.board(id)
+	if b == nil {
+		return nil, http.StatusNotFound
+	}
+	return b, http.StatusOK
+}
+
+func getBoardLists(d *data, id string, _ url.Values) (interface{}, int) {
+	if d.board(id) == nil {
+		return nil, http.StatusNotFound
+	}
+	return d.boardLists(id), http.StatusOK
+}
+
+func getBoardCards(d *data, id string, form url.Values) (interface{}, int) {
+	if d.board(id) == nil {
+		return nil, http.StatusNotFound
+	}
+	cards := d.cardsWhere(func(c *Card) bool { return c.IDBoard == id })
+	if form.Get("filter") == "all" {
+		cards = d.allCardsWhere(func(c *Card) bool { return c.IDBoard == id })
+	}
+	members, _ := strconv.ParseBool(form.Get("members"))
+	checklists := form.Get("checklists") == "all"
+	if !members && !checklists {
+		return cards, http.StatusOK
+	}
+	embedded := make([]Card, len(cards))
+	for i, c := range cards {
+		embedded[i] = *c
+		if members {
+			embedded[i].Members = []Member{}
+			for _, memberID := range c.IDMembers {
+				if m := d.member(memberID); m != nil {
+					embedded[i].Members = append(embedded[i].Members, *m)
+				}
+			}
+		}
+		if checklists {
+			embedded[i].Checklists = d.cardChecklists(c.ID)
+		}
+	}
+	return embedded, http.StatusOK
+}
+
+func getBoardLabels(d *data, id string, _ url.Values) (interface{}, int) {
+	if d.board(id) == nil {
+		return nil, http.StatusNotFound
+	}
+	labels := []*Label{}
+	for _, l := range d.labels {
+		if l.IDBoard == id {
+			labels = append(labels, l)
+		}
+	}
+	return labels, http.StatusOK
+}
+
+func getBoardMembers(d *data, id string, _ url.Values) (interface{}, int) {
+	b := d.board(id)
+	if b == nil {
+		return nil, http.StatusNotFound
+	}
+	members := []*Member{}
+	for _, memberID := range b.IDMembers {
+		if m := d.member(memberID); m != nil {
+			members = append(members, m)
+		}
+	}
+	return members, http.StatusOK
+}
+
+func getList(d *data, id string, _ url.Values) (interface{}, int) {
+	l := d.list(id)
+	if l == nil {
+		return nil, http.StatusNotFound
+	}
+	return l, http.StatusOK
+}
+
+func getListCards(d *data, id string, _ url.Values) (interface{}, int) {
+	if d.list(id) == nil {
+		return nil, http.StatusNotFound
+	}
+	return d.cardsWhere(func(c *Card) bool { return c.IDList == id }), http.StatusOK
+}
+
+func postList(d *data, _ string, form url.Values) (interface{}, int) {
+	boardID := form.Get("idBoard")
+	if d.board(boardID) == nil || form.Get("name") == "" {
+		return nil, http.StatusBadRequest
+	}
+	l := &List{ID: d.newID(), Name: form.Get("name"), IDBoard: boardID, Pos: d.lastPos(boardID, "")}
+	if _, found := form["pos"]; found {
+		pos, ok := d.parsePos(form.Get("pos"), boardID, "")
+		if !ok {
+			return nil, http.StatusBadRequest
+		}
+		l.Pos = pos
+	}
+	d.lists = append(d.lists, l)
+	return l, http.StatusOK
+}
+
+func putList(d *data, id string, form url.Values) (interface{}, int) {
+	l := d.list(id)
+	if l == nil {
+		return nil, http.StatusNotFound
+	}
+	if _, found := form["name"]; found {
+		l.Name = form.Get("name")
+	}
+	if _, found := form["closed"]; found {
+		closed, err := strconv.ParseBool(form.Get("closed"))
+		if err != nil {
+			return nil, http.StatusBadRequest
+		}
+		l.Closed = closed
+	}
+	if _, found := form["pos"]; found {
+		pos, ok := d.parsePos(form.Get("pos"), l.IDBoard, "")
+		if !ok {
+			return nil, http.StatusBadRequest
+		}
+		l.Pos = pos
+	}
+	return l, http.StatusOK
+}
+
+func getCard(d *data, id string, _ url.Values) (interface{}, int) {
+	c := d.card(id)
+	if c == nil {
+		return nil, http.StatusNotFound
+	}
+	return c, http.StatusOK
+}
+
+func postCard(d *data, _ string, form url.Values) (interface{}, int) {
+	l := d.list(form.Get("idList"))
+	if l == nil {
+		return nil, http.StatusBadRequest
+	}
+	c := &Card{IDList: l.ID, IDMembers: []string{}, IDLabels: []string{}, Pos: d.lastPos(l.IDBoard, l.ID)}
+	if status := d.updateCard(c, form); status != http.StatusOK {
+		return nil, status
+	}
+	d.createCard(c, l.IDBoard)
+	return c, http.StatusOK
+}
+
+func putCard(d *data, id string, form url.Values) (interface{}, int) {
+	c := d.card(id)
+	if c == nil {
+		return nil, http.StatusNotFound
+	}
+	if status := d.updateCard(c, form); status != http.StatusOK {
+		return nil, status
+	}
+	c.Labels = d.cardLabels(c.IDLabels)
+	c.DateLastActivity = time.Now()
+	return c, http.StatusOK
+}
+
+// updateCard sets the fields of the card present in the form
+func (d *data) updateCard(c *Card, form url.Values) int {
+	if _, found := form["idList"]; found {
+		l := d.list(form.Get("idList"))
+		if l == nil {
+			return http.StatusBadRequest
+		}
+		c.IDList = l.ID
+		c.IDBoard = l.IDBoard
+	}
+	for field, value := range map[string]*string{"name": &c.Name, "desc": &c.Desc} {
+		if _, found := form[field]; found {
+			*value = form.Get(field)
+		}
+	}
+	for field, value := range map[string]*[]string{"idMembers": &c.IDMembers, "idLabels": &c.IDLabels} {
+		if _, found := form[field]; found {
+			*value = splitIDs(form.Get(field))
+		}
+	}
+	for field, value := range map[string]*bool{"closed": &c.Closed, "dueComplete": &c.DueComplete, "subscribed": &c.Subscribed} {
+		if _, found := form[field]; found {
+			b, err := strconv.ParseBool(form.Get(field))
+			if err != nil {
+				return http.StatusBadRequest
+			}
+			*value = b
+		}
+	}
+	if _, found := form["due"]; found {
+		c.Due = nil
+		if value := form.Get("due"); value != "" && value != "null" {
+			due, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return http.StatusBadRequest
+			}
+			c.Due = &due
+		}
+	}
+	if _, found := form["pos"]; found {
+		pos, ok := d.parsePos(form.Get("pos"), c.IDBoard, c.IDList)
+		if !ok {
+			return http.StatusBadRequest
+		}
+		c.Pos = pos
+	}
+	return http.StatusOK
+}
+
+func getCardActions(d *data, id string, _ url.Values) (interface{}, int) {
+	if d.card(id) == nil {
+		return nil, http.StatusNotFound
+	}
+	actions := []*Action{}
+	for i := len(d.actions) - 1; i >= 0; i-- {
+		if a := d.actions[i]; a.Data.Card != nil && a.Data.Card.ID == id {
+			actions = append(actions, a)
+		}
+	}
+	return actions, http.StatusOK
+}
+
+func postCardComment(d *data, id string, form url.Values) (interface{}, int) {
+	if form.Get("text") == "" {
+		return nil, http.StatusBadRequest
+	}
+	var memberID string
+	if len(d.members) > 0 {
+		memberID = d.members[0].ID
+	}
+	a := d.comment(id, memberID, form.Get("text"), time.Now())
+	if a == nil {
+		return nil, http.StatusNotFound
+	}
+	return a, http.StatusOK
+}
+
+// parsePos returns the position described by the value, either `top`, `bottom` or a number
+func (d *data) parsePos(value, boardID, listID string) (float64, bool) {
+	switch value {
+	case "top":
+		return d.firstPos(boardID, listID), true
+	case "bottom":
+		return d.lastPos(boardID, listID), true
+	}
+	pos, err := strconv.ParseFloat(value, 64)
+	return pos, err == nil && pos > 0
+}
+
+// splitIDs returns the ids of the comma separated list
+func splitIDs(value string) []string {
+	ids := []string{}
+	for _, id := range strings.Split(value, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,358 @@
+// Package fake provides an in-memory implementation of the subset of the trello API used by
+// trello-tui, for testing and for running the application without a trello account
+package fake
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/giannimassi/trello-tui/pkg/domain"
+)
+
+// Server is a fake trello API server backed by in-memory data, the API is served under `/1` as
+// done by trello, e.g. `URL() + "/1/members/me"`
+type Server struct {
+	server *httptest.Server
+
+	m    sync.Mutex
+	data data
+
+	latency     time.Duration
+	failNext    int // number of requests which fail with failStatus
+	failStatus  int
+	rateLimit   int // maximum number of requests per rateWindow, zero if unlimited
+	rateWindow  time.Duration
+	windowStart time.Time
+	windowCount int
+	requests    int
+}
+
+// NewServer starts and returns a new fake trello server with no data
+func NewServer() *Server {
+	s := &Server{}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// URL returns the base URL of the server
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+// APIURL returns the base URL of the API served, to be used as trello.Config.BaseURL
+func (s *Server) APIURL() string {
+	return s.server.URL + "/1"
+}
+
+// Close shuts down the server
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// SetLatency delays every response by the provided duration
+func (s *Server) SetLatency(latency time.Duration) {
+	s.m.Lock()
+	s.latency = latency
+	s.m.Unlock()
+}
+
+// FailNext makes the next n requests fail with the provided HTTP status, e.g. 500
+func (s *Server) FailNext(n, status int) {
+	s.m.Lock()
+	s.failNext = n
+	s.failStatus = status
+	s.m.Unlock()
+}
+
+// SetRateLimit makes the requests exceeding the limit within each window fail with status 429
+// and the seconds left in the window as Retry-After header, as done by trello, a zero limit
+// disables rate limiting
+func (s *Server) SetRateLimit(limit int, window time.Duration) {
+	s.m.Lock()
+	s.rateLimit = limit
+	s.rateWindow = window
+	s.windowStart = time.Time{}
+	s.windowCount = 0
+	s.m.Unlock()
+}
+
+// Requests returns the number of requests received
+func (s *Server) Requests() int {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.requests
+}
+
+// AddMember adds a member and returns its id
+func (s *Server) AddMember(username, fullName string) string {
+	s.m.Lock()
+	defer s.m.Unlock()
+	var initials string
+	for _, word := range strings.Fields(fullName) {
+		initials += string([]rune(word)[0])
+	}
+	m := &Member{ID: s.data.newID(), Username: username, FullName: fullName, Initials: initials}
+	s.data.members = append(s.data.members, m)
+	return m.ID
+}
+
+// AddBoard adds a board shared by the members with the provided ids and returns its id
+func (s *Server) AddBoard(name, desc string, memberIDs ...string) string {
+	s.m.Lock()
+	defer s.m.Unlock()
+	id := s.data.newID()
+	b := &Board{
+		ID:        id,
+		Name:      name,
+		Desc:      desc,
+		URL:       "https://trello.com/b/" + shortLink(id) + "/" + slug(name),
+		ShortURL:  "https://trello.com/b/" + shortLink(id),
+		IDMembers: memberIDs,
+	}
+	s.data.boards = append(s.data.boards, b)
+	return b.ID
+}
+
+// AddList adds a list at the end of the board and returns its id
+func (s *Server) AddList(boardID, name string) string {
+	s.m.Lock()
+	defer s.m.Unlock()
+	l := &List{ID: s.data.newID(), Name: name, IDBoard: boardID, Pos: s.data.lastPos(boardID, "")}
+	s.data.lists = append(s.data.lists, l)
+	return l.ID
+}
+
+// AddLabel adds a label to the board and returns its id
+func (s *Server) AddLabel(boardID, name, color string) string {
+	s.m.Lock()
+	defer s.m.Unlock()
+	l := &Label{ID: s.data.newID(), IDBoard: boardID, Name: name, Color: color}
+	s.data.labels = append(s.data.labels, l)
+	return l.ID
+}
+
+// AddCard adds a card at the end of the list and returns its id, the content of the card is
+// taken from the template provided, while the ids, number, position and links are assigned
+func (s *Server) AddCard(listID string, template Card) string {
+	s.m.Lock()
+	defer s.m.Unlock()
+	l := s.data.list(listID)
+	if l == nil {
+		return ""
+	}
+	c := template
+	c.IDList = listID
+	c.Pos = s.data.lastPos(l.IDBoard, listID)
+	s.data.createCard(&c, l.IDBoard)
+	return c.ID
+}
+
+// AddComment adds a comment by the member to the card and returns the id of the action
+func (s *Server) AddComment(cardID, memberID, text string) string {
+	s.m.Lock()
+	defer s.m.Unlock()
+	a := s.data.comment(cardID, memberID, text, time.Now())
+	if a == nil {
+		return ""
+	}
+	return a.ID
+}
+
+// AddNotification adds an unread notification for the member about the card, caused by the
+// creator, and returns its id
+func (s *Server) AddNotification(memberID, notificationType, creatorID, cardID, text string) string {
+	s.m.Lock()
+	defer s.m.Unlock()
+	n := &Notification{
+		ID:              s.data.newID(),
+		IDMember:        memberID,
+		Type:            notificationType,
+		Date:            time.Now(),
+		Unread:          true,
+		IDMemberCreator: creatorID,
+		Data:            ActionData{Text: text},
+	}
+	if creator := s.data.member(creatorID); creator != nil {
+		n.MemberCreator = *creator
+	}
+	if c := s.data.card(cardID); c != nil {
+		n.Data.Card = &Ref{ID: c.ID, Name: c.Name, ShortLink: c.ShortLink, IDShort: c.IDShort}
+		if b := s.data.board(c.IDBoard); b != nil {
+			n.Data.Board = &Ref{ID: b.ID, Name: b.Name}
+		}
+	}
+	s.data.notifications = append(s.data.notifications, n)
+	return n.ID
+}
+
+// AddChecklist adds a checklist with the provided items to the card and returns its id, items
+// prefixed by `[x] ` are complete
+func (s *Server) AddChecklist(cardID, name string, items ...string) string {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if s.data.card(cardID) == nil {
+		return ""
+	}
+	cl := &Checklist{
+		ID:         s.data.newID(),
+		IDCard:     cardID,
+		Name:       name,
+		Pos:        float64(len(s.data.cardChecklists(cardID))+1) * domain.PosStep,
+		CheckItems: []CheckItem{},
+	}
+	for i, item := range items {
+		state := "incomplete"
+		if strings.HasPrefix(item, "[x] ") {
+			item, state = strings.TrimPrefix(item, "[x] "), "complete"
+		}
+		cl.CheckItems = append(cl.CheckItems, CheckItem{ID: s.data.newID(), Name: item, State: state, Pos: float64(i+1) * domain.PosStep})
+	}
+	s.data.checklists = append(s.data.checklists, cl)
+	return cl.ID
+}
+
+// UpdateCard applies the update to the card with the corresponding id, as if it was modified by
+// another member, it returns false if the card is not found
+func (s *Server) UpdateCard(id string, update func(c *Card)) bool {
+	s.m.Lock()
+	defer s.m.Unlock()
+	c := s.data.card(id)
+	if c == nil {
+		return false
+	}
+	update(c)
+	c.Labels = s.data.cardLabels(c.IDLabels)
+	c.DateLastActivity = time.Now()
+	return true
+}
+
+// createCard assigns the ids, number and links of a new card and adds it to the board
+func (d *data) createCard(c *Card, boardID string) {
+	c.ID = d.newID()
+	c.IDBoard = boardID
+	c.IDShort = d.nextIDShort(boardID)
+	c.ShortLink = shortLink(c.ID)
+	c.ShortURL = "https://trello.com/c/" + c.ShortLink
+	c.URL = c.ShortURL + "/" + strconv.Itoa(c.IDShort) + "-" + slug(c.Name)
+	c.Labels = d.cardLabels(c.IDLabels)
+	c.DateLastActivity = time.Now()
+	d.cards = append(d.cards, c)
+}
+
+// cardLabels returns the labels with the provided ids
+func (d *data) cardLabels(ids []string) []Label {
+	labels := []Label{}
+	for _, id := range ids {
+		if l := d.label(id); l != nil {
+			labels = append(labels, *l)
+		}
+	}
+	return labels
+}
+
+// comment adds a comment by the member to the card
+func (d *data) comment(cardID, memberID, text string, date time.Time) *Action {
+	c := d.card(cardID)
+	if c == nil {
+		return nil
+	}
+	a := &Action{
+		ID:              d.newID(),
+		Type:            "commentCard",
+		Date:            date,
+		IDMemberCreator: memberID,
+		Data: ActionData{
+			Text: text,
+			Card: &Ref{ID: c.ID, Name: c.Name, ShortLink: c.ShortLink, IDShort: c.IDShort},
+		},
+	}
+	if m := d.member(memberID); m != nil {
+		a.MemberCreator = *m
+	}
+	if b := d.board(c.IDBoard); b != nil {
+		a.Data.Board = &Ref{ID: b.ID, Name: b.Name}
+	}
+	if l := d.list(c.IDList); l != nil {
+		a.Data.List = &Ref{ID: l.ID, Name: l.Name}
+	}
+	d.actions = append(d.actions, a)
+	c.Badges.Comments++
+	c.DateLastActivity = date
+	return a
+}
+
+// serveHTTP simulates latency, failures and rate limiting before handling the request
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.m.Lock()
+	s.requests++
+	latency := s.latency
+	status, retryAfter := s.failure(time.Now())
+	s.m.Unlock()
+
+	time.Sleep(latency)
+	if status != 0 {
+		if retryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int((retryAfter+time.Second-1)/time.Second)))
+		}
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+	query := r.URL.Query()
+	if query.Get("key") == "" || query.Get("token") == "" {
+		http.Error(w, "invalid key", http.StatusUnauthorized)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/1/"), "/")
+
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.route(w, r, strings.Split(path, "/"))
+}
+
+// failure returns the HTTP status of the simulated failure of the request, zero if none, and
+// the time left before the rate limiting window is over if the request exceeds the limit
+func (s *Server) failure(now time.Time) (int, time.Duration) {
+	if s.failNext > 0 {
+		s.failNext--
+		return s.failStatus, 0
+	}
+	if s.rateLimit > 0 {
+		if now.Sub(s.windowStart) >= s.rateWindow {
+			s.windowStart = now
+			s.windowCount = 0
+		}
+		s.windowCount++
+		if s.windowCount > s.rateLimit {
+			return http.StatusTooManyRequests, s.windowStart.Add(s.rateWindow).Sub(now)
+		}
+	}
+	return 0, 0
+}
+
+// writeJSON writes the value encoded as JSON
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// shortLink returns the short link of a board or card, derived from its id
+func shortLink(id string) string {
+	return id[len(id)-8:]
+}
+
+// slug returns the name formatted for URLs, as done by trello
+func slug(name string) string {
+	fields := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	})
+	return strings.Join(fields, "-")
+}
@@ -0,0 +1,105 @@
+package trello
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// apiPrefix is the path prefix of the requests made by the trello package
+	apiPrefix = "/1"
+	// maxRetries is the number of times a failed request is retried
+	maxRetries = 2
+	// retryWait is the time waited before the first retry, doubled for each of the following ones
+	retryWait = 250 * time.Millisecond
+	// maxRetryWait is the maximum time waited before retrying, even if trello asks for longer
+	maxRetryWait = 10 * time.Second
+)
+
+// baseURLTransport sends the requests made by the trello package, which always target
+// api.trello.com, to another server, e.g. a fake one
+type baseURLTransport struct {
+	base     *url.URL
+	delegate http.RoundTripper
+}
+
+// RoundTrip implements the http.RoundTripper interface
+func (t *baseURLTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rewritten := new(http.Request)
+	*rewritten = *req
+	u := *req.URL
+	u.Scheme = t.base.Scheme
+	u.Host = t.base.Host
+	u.Path = strings.TrimSuffix(t.base.Path, "/") + strings.TrimPrefix(u.Path, apiPrefix)
+	rewritten.URL = &u
+	rewritten.Host = ""
+	return t.delegate.RoundTrip(rewritten)
+}
+
+// retryTransport retries the requests rejected by trello's rate limiting, with status 429, and
+// the GET requests failing with a server error, which can be repeated safely, waiting longer
+// after each attempt or as long as requested by the Retry-After header
+type retryTransport struct {
+	delegate http.RoundTripper
+}
+
+// RoundTrip implements the http.RoundTripper interface
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	wait := retryWait
+	for attempt := 0; ; attempt++ {
+		attemptReq := req
+		if attempt > 0 && req.Body != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = new(http.Request)
+			*attemptReq = *req
+			attemptReq.Body = body
+		}
+		resp, err := t.delegate.RoundTrip(attemptReq)
+		if err != nil || attempt == maxRetries || !retryable(req, resp.StatusCode) {
+			return resp, err
+		}
+		if after := retryAfter(resp); after > 0 {
+			wait = after
+		}
+		// the body is drained so that the connection can be reused
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+}
+
+// retryable returns true if the request which failed with the provided status can be retried,
+// the request body must be available again for retrying requests with a body
+func retryable(req *http.Request, status int) bool {
+	if req.Body != nil && req.GetBody == nil {
+		return false
+	}
+	return status == http.StatusTooManyRequests || status >= 500 && req.Method == http.MethodGet
+}
+
+// retryAfter returns the time to wait before retrying as requested by the response, zero if
+// not specified
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	if wait := time.Duration(seconds) * time.Second; wait < maxRetryWait {
+		return wait
+	}
+	return maxRetryWait
+}